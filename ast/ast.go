@@ -35,7 +35,7 @@ type Decl interface {
 // Comments
 //
 
-// A Comment node represents a single /*-style comment
+// A Comment node represents a single //-style or /*-style comment
 type Comment struct {
 	Slash mytoken.Pos // position of "/" starting the comment
 	Text  string      // comment text
@@ -76,7 +76,17 @@ func (g *CommentGroup) Text() string {
 	lines := make([]string, 0, 10)
 	for _, c := range comments {
 		// remove comment markers
-		c = c[2 : len(c)-2]
+		switch c[1] {
+		case '/':
+			//-style comment, without the newline
+			c = c[2:]
+			if len(c) > 0 && c[0] == ' ' {
+				c = c[1:]
+			}
+		case '*':
+			/*-style comment */
+			c = c[2 : len(c)-2]
+		}
 		// Split on newlines
 		cl := strings.Split(c, "\n")
 
@@ -430,6 +440,7 @@ type (
 	//	CONST,VAR *ValueSpec
 	//  TYPE *TypeSpec
 	GenDecl struct {
+		Doc    *CommentGroup // associated documentation; or nil
 		TokPos mytoken.Pos
 		Tok    mytoken.Token // const, type, var
 		Lparen mytoken.Pos   // position of '(', if any
@@ -456,6 +467,9 @@ func (d *GenDecl) declNode() {}
 //
 
 type File struct {
-	Decls      []Decl   // top-level declarations; or nil
-	Unresolved []*Ident // unresolved identifiers in this file
+	Decls      []Decl                 // top-level declarations; or nil
+	Stmts      []Stmt                 // top-level statements; or nil
+	Unresolved []*Ident               // unresolved identifiers in this file
+	Comments   []*CommentGroup        // list of all comments in the source file
+	Docs       map[Stmt]*CommentGroup // the comments preceding the statements, at all levels
 }
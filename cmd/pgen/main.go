@@ -0,0 +1,102 @@
+// Command pgen generates the parsing tables of a grammar file as Go
+// source, so that parsers need not build them when they start.
+//
+// Usage:
+//
+//	pgen [flags] grammar
+//
+// The grammar file format is described in package parser. The output
+// declares a variable of type *parser.Tables; its Load method returns
+// the grammar and its parsing table. The flags are:
+//
+//	-o file   write the output to file instead of standard output
+//	-pkg name package of the output (default parser)
+//	-var name name of the variable (default tables)
+//	-lr1      build a canonical LR(1) table instead of an LALR(1) one
+//	-strict   fail on conflicts that precedence does not resolve
+//	-v        report all conflicts on standard error
+package main
+
+import (
+	"flag"
+	"fmt"
+	"myGo/codegen"
+	"myGo/parser"
+	"os"
+	"path/filepath"
+)
+
+var (
+	output  = flag.String("o", "", "output file")
+	pkg     = flag.String("pkg", "parser", "package name")
+	name    = flag.String("var", "tables", "variable name")
+	lr1     = flag.Bool("lr1", false, "build a canonical LR(1) table")
+	strict  = flag.Bool("strict", false, "fail on unresolved conflicts")
+	verbose = flag.Bool("v", false, "report all conflicts")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: pgen [flags] grammar")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, "pgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	grammar, err := parser.LoadGrammar(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s:%v", path, err)
+	}
+
+	actions, conflicts, err := parser.Generate(grammar, parser.Options{LALR: !*lr1, Strict: *strict})
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	unresolved := 0
+	for _, c := range conflicts {
+		if !c.Resolved {
+			unresolved++
+		}
+		if *verbose {
+			fmt.Fprintln(os.Stderr, c)
+		}
+	}
+	if unresolved > 0 {
+		fmt.Fprintf(os.Stderr, "pgen: %s: %d conflicts resolved by default\n", path, unresolved)
+	}
+
+	w := &codegen.Writer{}
+	w.Linef("// Code generated by pgen from %s; DO NOT EDIT.", filepath.Base(path))
+	w.Line("")
+	w.Linef("package %s", *pkg)
+	w.Line("")
+	if *pkg != "parser" {
+		w.Line(`import "myGo/parser"`)
+		w.Line("")
+	}
+	parser.NewTables(grammar, actions).WriteGo(w, *name, *pkg)
+	src, err := w.Fmt()
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*output, src, 0666)
+}
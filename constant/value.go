@@ -0,0 +1,236 @@
+// Package constant implements the values of the constant expressions of
+// myGo programs: booleans and integers of arbitrary precision. The
+// operations fold only constant operands; an operand whose value is not
+// known at compile time makes the result Unknown.
+package constant
+
+import (
+	"fmt"
+	"math/big"
+	"myGo/mytoken"
+)
+
+// Kind specifies the kind of value represented by a Value.
+type Kind int
+
+const (
+	// unknown values
+	Unknown Kind = iota
+
+	// non-numeric values
+	Bool
+
+	// numeric values
+	Int
+)
+
+var kindStrings = [...]string{
+	Unknown: "unknown",
+	Bool:    "bool",
+	Int:     "int",
+}
+
+func (k Kind) String() string { return kindStrings[k] }
+
+// A Value represents the value of a myGo constant.
+type Value interface {
+	// Kind returns the value kind.
+	Kind() Kind
+
+	// String returns the value in myGo syntax: the decimal digits of
+	// an Int, true or false; "unknown" for Unknown values.
+	String() string
+
+	implementsValue()
+}
+
+type (
+	unknownVal struct{}
+	boolVal    bool
+	intVal     struct{ val *big.Int } // never nil
+)
+
+func (unknownVal) Kind() Kind { return Unknown }
+func (boolVal) Kind() Kind    { return Bool }
+func (intVal) Kind() Kind     { return Int }
+
+func (unknownVal) String() string { return "unknown" }
+func (x boolVal) String() string  { return fmt.Sprint(bool(x)) }
+func (x intVal) String() string   { return x.val.String() }
+
+func (unknownVal) implementsValue() {}
+func (boolVal) implementsValue()    {}
+func (intVal) implementsValue()     {}
+
+// MakeUnknown returns the Unknown value.
+func MakeUnknown() Value { return unknownVal{} }
+
+// MakeBool returns the Bool value for b.
+func MakeBool(b bool) Value { return boolVal(b) }
+
+// MakeInt64 returns the Int value for x.
+func MakeInt64(x int64) Value { return intVal{big.NewInt(x)} }
+
+// MakeFromLiteral returns the Int value of the INT literal lit, or
+// Unknown if tok is not INT or lit is malformed.
+func MakeFromLiteral(lit string, tok mytoken.Token) Value {
+	if tok != mytoken.INT {
+		return unknownVal{}
+	}
+	v, err := mytoken.LiteralValue(tok, lit)
+	if err != nil {
+		return unknownVal{}
+	}
+	return intVal{v.(*big.Int)}
+}
+
+// BoolVal returns the Go boolean value of x, which must be a Bool or
+// an Unknown. If x is Unknown, the result is false.
+func BoolVal(x Value) bool {
+	switch x := x.(type) {
+	case boolVal:
+		return bool(x)
+	case unknownVal:
+		return false
+	}
+	panic(fmt.Sprintf("%v not a Bool", x))
+}
+
+// Int64Val returns the Go int64 value of x and whether the result is
+// exact. x must be an Int or an Unknown. If x is Unknown, the result
+// is (0, false).
+func Int64Val(x Value) (int64, bool) {
+	switch x := x.(type) {
+	case intVal:
+		return x.val.Int64(), x.val.IsInt64()
+	case unknownVal:
+		return 0, false
+	}
+	panic(fmt.Sprintf("%v not an Int", x))
+}
+
+// Sign returns -1, 0, or 1 depending on whether x < 0, x == 0, or
+// x > 0; x must be an Int or an Unknown. If x is Unknown, the result
+// is 1.
+func Sign(x Value) int {
+	switch x := x.(type) {
+	case intVal:
+		return x.val.Sign()
+	case unknownVal:
+		return 1
+	}
+	panic(fmt.Sprintf("%v not an Int", x))
+}
+
+// Fits reports whether the Int x can be stored in a signed integer of
+// size bytes. Values other than Ints always fit.
+func Fits(x Value, size int) bool {
+	v, ok := x.(intVal)
+	if !ok {
+		return true
+	}
+	if v.val.Sign() < 0 {
+		// -1<<(8*size-1) takes as many bits as 1<<(8*size-1) - 1
+		return new(big.Int).Not(v.val).BitLen() < 8*size
+	}
+	return v.val.BitLen() < 8*size
+}
+
+// UnaryOp returns the result of the unary expression op y. The
+// operation must be defined for the operand: + and - for Ints, ! for
+// Bools. If y is Unknown, the result is Unknown.
+func UnaryOp(op mytoken.Token, y Value) Value {
+	switch y := y.(type) {
+	case unknownVal:
+		return y
+	case boolVal:
+		if op == mytoken.NOT {
+			return !y
+		}
+	case intVal:
+		switch op {
+		case mytoken.ADD:
+			return y
+		case mytoken.SUB:
+			return intVal{new(big.Int).Neg(y.val)}
+		}
+	}
+	panic(fmt.Sprintf("invalid unary operation %s%v", op, y))
+}
+
+// BinaryOp returns the result of the binary expression x op y. The
+// operation must be defined for the operands: + - * / for Ints, where
+// / truncates toward zero, && and || for Bools. If either operand is
+// Unknown, the result is Unknown. Division by zero panics; callers
+// report it first.
+func BinaryOp(x Value, op mytoken.Token, y Value) Value {
+	if x.Kind() == Unknown || y.Kind() == Unknown {
+		return unknownVal{}
+	}
+	switch x := x.(type) {
+	case boolVal:
+		if y, ok := y.(boolVal); ok {
+			switch op {
+			case mytoken.LAND:
+				return x && y
+			case mytoken.LOR:
+				return x || y
+			}
+		}
+	case intVal:
+		if y, ok := y.(intVal); ok {
+			z := new(big.Int)
+			switch op {
+			case mytoken.ADD:
+				return intVal{z.Add(x.val, y.val)}
+			case mytoken.SUB:
+				return intVal{z.Sub(x.val, y.val)}
+			case mytoken.MUL:
+				return intVal{z.Mul(x.val, y.val)}
+			case mytoken.QUO:
+				return intVal{z.Quo(x.val, y.val)}
+			}
+		}
+	}
+	panic(fmt.Sprintf("invalid binary operation %v %s %v", x, op, y))
+}
+
+// Compare returns the result of the comparison x op y. The comparison
+// must be defined for the operands: == and != for values of the same
+// kind, < <= > >= for Ints. If either operand is Unknown, the result
+// is false.
+func Compare(x Value, op mytoken.Token, y Value) bool {
+	if x.Kind() == Unknown || y.Kind() == Unknown {
+		return false
+	}
+	switch x := x.(type) {
+	case boolVal:
+		if y, ok := y.(boolVal); ok {
+			switch op {
+			case mytoken.EQL:
+				return x == y
+			case mytoken.NEQ:
+				return x != y
+			}
+		}
+	case intVal:
+		if y, ok := y.(intVal); ok {
+			c := x.val.Cmp(y.val)
+			switch op {
+			case mytoken.EQL:
+				return c == 0
+			case mytoken.NEQ:
+				return c != 0
+			case mytoken.LSS:
+				return c < 0
+			case mytoken.LEQ:
+				return c <= 0
+			case mytoken.GTR:
+				return c > 0
+			case mytoken.GEQ:
+				return c >= 0
+			}
+		}
+	}
+	panic(fmt.Sprintf("invalid comparison %v %s %v", x, op, y))
+}
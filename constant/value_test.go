@@ -0,0 +1,113 @@
+package constant
+
+import (
+	"myGo/mytoken"
+	"testing"
+)
+
+func TestOps(t *testing.T) {
+	big := MakeFromLiteral("1_000_000_000_000_000_000_000", mytoken.INT)
+	for _, test := range []struct {
+		got  Value
+		want string
+	}{
+		{MakeFromLiteral("0x10", mytoken.INT), "16"},
+		{MakeFromLiteral("0x", mytoken.INT), "unknown"},
+		{MakeFromLiteral("1.5", mytoken.FLOAT), "unknown"},
+		{BinaryOp(MakeInt64(7), mytoken.ADD, MakeInt64(5)), "12"},
+		{BinaryOp(MakeInt64(7), mytoken.SUB, MakeInt64(5)), "2"},
+		{BinaryOp(MakeInt64(7), mytoken.MUL, MakeInt64(5)), "35"},
+		{BinaryOp(MakeInt64(7), mytoken.QUO, MakeInt64(-2)), "-3"},
+		{BinaryOp(MakeInt64(-7), mytoken.QUO, MakeInt64(2)), "-3"},
+		{BinaryOp(big, mytoken.MUL, big), "1000000000000000000000000000000000000000000"},
+		{BinaryOp(MakeBool(true), mytoken.LAND, MakeBool(false)), "false"},
+		{BinaryOp(MakeBool(true), mytoken.LOR, MakeBool(false)), "true"},
+		{BinaryOp(MakeInt64(1), mytoken.ADD, MakeUnknown()), "unknown"},
+		{BinaryOp(MakeUnknown(), mytoken.QUO, MakeInt64(0)), "unknown"},
+		{UnaryOp(mytoken.SUB, big), "-1000000000000000000000"},
+		{UnaryOp(mytoken.ADD, MakeInt64(3)), "3"},
+		{UnaryOp(mytoken.NOT, MakeBool(false)), "true"},
+		{UnaryOp(mytoken.SUB, MakeUnknown()), "unknown"},
+	} {
+		if s := test.got.String(); s != test.want {
+			t.Errorf("got %s, want %s", s, test.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	one, two := MakeInt64(1), MakeInt64(2)
+	for _, test := range []struct {
+		x    Value
+		op   mytoken.Token
+		y    Value
+		want bool
+	}{
+		{one, mytoken.LSS, two, true},
+		{one, mytoken.GTR, two, false},
+		{two, mytoken.GEQ, two, true},
+		{one, mytoken.LEQ, one, true},
+		{one, mytoken.EQL, MakeInt64(1), true},
+		{one, mytoken.NEQ, two, true},
+		{MakeBool(true), mytoken.EQL, MakeBool(true), true},
+		{MakeBool(true), mytoken.NEQ, MakeBool(true), false},
+		{one, mytoken.EQL, MakeUnknown(), false},
+		{MakeUnknown(), mytoken.NEQ, one, false},
+	} {
+		if got := Compare(test.x, test.op, test.y); got != test.want {
+			t.Errorf("%s %s %s: got %v, want %v", test.x, test.op, test.y, got, test.want)
+		}
+	}
+}
+
+func TestFits(t *testing.T) {
+	for _, test := range []struct {
+		lit  string
+		neg  bool
+		size int
+		want bool
+	}{
+		{"127", false, 1, true},
+		{"128", false, 1, false},
+		{"128", true, 1, true},
+		{"129", true, 1, false},
+		{"2147483647", false, 4, true},
+		{"2147483648", false, 4, false},
+		{"2147483648", true, 4, true},
+		{"9223372036854775808", true, 8, true},
+		{"9223372036854775808", false, 8, false},
+		{"0", false, 1, true},
+	} {
+		x := MakeFromLiteral(test.lit, mytoken.INT)
+		if test.neg {
+			x = UnaryOp(mytoken.SUB, x)
+		}
+		if got := Fits(x, test.size); got != test.want {
+			t.Errorf("Fits(%s, %d) = %v, want %v", x, test.size, got, test.want)
+		}
+	}
+	if !Fits(MakeBool(true), 1) || !Fits(MakeUnknown(), 1) {
+		t.Errorf("values other than Ints must fit")
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	if n, exact := Int64Val(MakeInt64(-42)); n != -42 || !exact {
+		t.Errorf("Int64Val(-42) = %d, %v", n, exact)
+	}
+	if _, exact := Int64Val(MakeFromLiteral("99999999999999999999", mytoken.INT)); exact {
+		t.Errorf("Int64Val(99999999999999999999) is exact")
+	}
+	if _, exact := Int64Val(MakeUnknown()); exact {
+		t.Errorf("Int64Val(unknown) is exact")
+	}
+	if !BoolVal(MakeBool(true)) || BoolVal(MakeUnknown()) {
+		t.Errorf("wrong BoolVal")
+	}
+	if Sign(MakeInt64(-3)) != -1 || Sign(MakeInt64(0)) != 0 || Sign(MakeUnknown()) != 1 {
+		t.Errorf("wrong Sign")
+	}
+	if k := MakeInt64(1).Kind(); k != Int || k.String() != "int" {
+		t.Errorf("got kind %s, want int", k)
+	}
+}
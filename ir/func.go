@@ -0,0 +1,61 @@
+package ir
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A Func is the code of a function: its instructions in order. A myGo
+// program is the single function main.
+type Func struct {
+	Name   string
+	Instrs []Instr
+
+	temps  int // number of temporaries created
+	labels int // number of labels created
+}
+
+// NewFunc returns a new function with no instructions.
+func NewFunc(name string) *Func {
+	return &Func{Name: name}
+}
+
+// Emit appends instr to the instructions of f.
+func (f *Func) Emit(instr Instr) {
+	f.Instrs = append(f.Instrs, instr)
+}
+
+// NewTemp returns a new temporary of f: t0, t1, and so on.
+func (f *Func) NewTemp() Temp {
+	t := Temp{f.temps}
+	f.temps++
+	return t
+}
+
+// NewLabel returns a new label of f, L0, L1, and so on. The label is
+// not placed: emit it where it belongs.
+func (f *Func) NewLabel() *Label {
+	l := &Label{"L" + strconv.Itoa(f.labels)}
+	f.labels++
+	return l
+}
+
+// String returns the instructions of f in textual form, one per line.
+func (f *Func) String() string {
+	var buf strings.Builder
+	Fprint(&buf, f)
+	return buf.String()
+}
+
+// Fprint writes the instructions of f to w in textual form, one per
+// line.
+func Fprint(w io.Writer, f *Func) error {
+	bw := bufio.NewWriter(w)
+	for _, instr := range f.Instrs {
+		bw.WriteString(instr.String())
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
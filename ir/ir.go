@@ -0,0 +1,172 @@
+// Package ir defines the intermediate representation of myGo programs:
+// three-address code. A Func holds a list of instructions, each with at
+// most one operator, which read and write operands: temporaries,
+// variables and constants. Control flows from an instruction to the
+// next, unless a jump transfers it to a label.
+package ir
+
+import (
+	"fmt"
+	"myGo/constant"
+	"myGo/mytoken"
+)
+
+// ----------------------------------------------------------------------------
+// Operands
+
+// An Operand is a value an instruction reads or writes.
+type Operand interface {
+	String() string
+	operand()
+}
+
+type (
+	// A Temp is a temporary, holding an intermediate result.
+	Temp struct {
+		Num int // t0, t1, ... in the order of creation
+	}
+
+	// A Var is a variable of the program.
+	Var struct {
+		Name string
+	}
+
+	// A Const is a constant; it can only be read.
+	Const struct {
+		Value constant.Value
+	}
+)
+
+func (x Temp) String() string  { return fmt.Sprintf("t%d", x.Num) }
+func (x Var) String() string   { return x.Name }
+func (x Const) String() string { return x.Value.String() }
+
+func (Temp) operand()  {}
+func (Var) operand()   {}
+func (Const) operand() {}
+
+// ----------------------------------------------------------------------------
+// Instructions
+
+// An Instr is an instruction. String returns it in the textual form of
+// the three-address code.
+type Instr interface {
+	String() string
+	instr()
+}
+
+type (
+	// A BinOp computes Dst = X Op Y. Op is one of the arithmetic
+	// operators + - * /, the logical operators && || or the
+	// comparisons == != > <.
+	BinOp struct {
+		Dst  Operand
+		Op   mytoken.Token
+		X, Y Operand
+	}
+
+	// A UnOp computes Dst = Op X. Op is - or !.
+	UnOp struct {
+		Dst Operand
+		Op  mytoken.Token
+		X   Operand
+	}
+
+	// A Copy assigns Dst = Src. Define is set if the copy initializes
+	// the variable Dst, declared by :=.
+	Copy struct {
+		Dst, Src Operand
+		Define   bool
+	}
+
+	// A Load reads an array element: Dst = Base[Index].
+	Load struct {
+		Dst, Base, Index Operand
+	}
+
+	// A Store writes an array element: Base[Index] = Src.
+	Store struct {
+		Base, Index, Src Operand
+	}
+
+	// A Jump transfers control to Target.
+	Jump struct {
+		Target *Label
+	}
+
+	// A CondJump transfers control to Target if Cond is false.
+	CondJump struct {
+		Cond   Operand
+		Target *Label
+	}
+
+	// A Label marks the position of the next instruction; it is the
+	// target of jumps.
+	Label struct {
+		Name string
+	}
+)
+
+// opStrings are the operators as the three-address code spells them.
+var opStrings = map[mytoken.Token]string{
+	mytoken.ADD:  "+",
+	mytoken.SUB:  "-",
+	mytoken.MUL:  "*",
+	mytoken.QUO:  "/",
+	mytoken.LAND: "and",
+	mytoken.LOR:  "or",
+	mytoken.EQL:  "eq",
+	mytoken.NEQ:  "neq",
+	mytoken.GTR:  "lg",
+	mytoken.LSS:  "le",
+	mytoken.NOT:  "not",
+}
+
+func opString(op mytoken.Token) string {
+	if s, ok := opStrings[op]; ok {
+		return s
+	}
+	return op.String()
+}
+
+func (i *BinOp) String() string {
+	return fmt.Sprintf("%s = %s %s %s", i.Dst, i.X, opString(i.Op), i.Y)
+}
+
+func (i *UnOp) String() string {
+	return fmt.Sprintf("%s = %s%s", i.Dst, opString(i.Op), i.X)
+}
+
+func (i *Copy) String() string {
+	if i.Define {
+		return fmt.Sprintf("%s  =  %s", i.Dst, i.Src)
+	}
+	return fmt.Sprintf("%s = %s", i.Dst, i.Src)
+}
+
+func (i *Load) String() string {
+	return fmt.Sprintf("%s = %s[%s]", i.Dst, i.Base, i.Index)
+}
+
+func (i *Store) String() string {
+	return fmt.Sprintf("%s[%s] = %s", i.Base, i.Index, i.Src)
+}
+
+func (i *Jump) String() string {
+	return "goto  " + i.Target.Name
+}
+
+func (i *CondJump) String() string {
+	return fmt.Sprintf("if  %s .false goto  %s", i.Cond, i.Target.Name)
+}
+
+func (i *Label) String() string { return i.Name }
+
+func (*BinOp) instr()    {}
+func (*UnOp) instr()     {}
+func (*Copy) instr()     {}
+func (*Load) instr()     {}
+func (*Store) instr()    {}
+func (*Jump) instr()     {}
+func (*CondJump) instr() {}
+func (*Label) instr()    {}
@@ -0,0 +1,78 @@
+package ir
+
+import (
+	"bytes"
+	"myGo/constant"
+	"myGo/mytoken"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	f := NewFunc("main")
+	t0, t1 := f.NewTemp(), f.NewTemp()
+	l0, l1 := f.NewLabel(), f.NewLabel()
+	i, a := Var{"i"}, Var{"a"}
+	two := Const{constant.MakeInt64(2)}
+	for _, test := range []struct {
+		instr Instr
+		want  string
+	}{
+		{&BinOp{Dst: t0, Op: mytoken.ADD, X: i, Y: two}, "t0 = i + 2"},
+		{&BinOp{Dst: t0, Op: mytoken.QUO, X: i, Y: two}, "t0 = i / 2"},
+		{&BinOp{Dst: t1, Op: mytoken.LAND, X: t0, Y: i}, "t1 = t0 and i"},
+		{&BinOp{Dst: t1, Op: mytoken.LOR, X: t0, Y: i}, "t1 = t0 or i"},
+		{&BinOp{Dst: t1, Op: mytoken.EQL, X: t0, Y: i}, "t1 = t0 eq i"},
+		{&BinOp{Dst: t1, Op: mytoken.NEQ, X: t0, Y: i}, "t1 = t0 neq i"},
+		{&BinOp{Dst: t1, Op: mytoken.GTR, X: t0, Y: i}, "t1 = t0 lg i"},
+		{&BinOp{Dst: t1, Op: mytoken.LSS, X: t0, Y: i}, "t1 = t0 le i"},
+		{&UnOp{Dst: t0, Op: mytoken.SUB, X: i}, "t0 = -i"},
+		{&UnOp{Dst: t0, Op: mytoken.NOT, X: i}, "t0 = noti"},
+		{&Copy{Dst: i, Src: t0, Define: true}, "i  =  t0"},
+		{&Copy{Dst: i, Src: two}, "i = 2"},
+		{&Load{Dst: t0, Base: a, Index: i}, "t0 = a[i]"},
+		{&Store{Base: a, Index: two, Src: t1}, "a[2] = t1"},
+		{&Jump{Target: l0}, "goto  L0"},
+		{&CondJump{Cond: t1, Target: l1}, "if  t1 .false goto  L1"},
+		{l1, "L1"},
+	} {
+		if got := test.instr.String(); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestFprint(t *testing.T) {
+	f := NewFunc("main")
+	i := Var{"i"}
+	loop, done := f.NewLabel(), f.NewLabel()
+	f.Emit(&Copy{Dst: i, Src: Const{constant.MakeInt64(0)}, Define: true})
+	f.Emit(loop)
+	t0 := f.NewTemp()
+	f.Emit(&BinOp{Dst: t0, Op: mytoken.LSS, X: i, Y: Const{constant.MakeInt64(10)}})
+	f.Emit(&CondJump{Cond: t0, Target: done})
+	t1 := f.NewTemp()
+	f.Emit(&BinOp{Dst: t1, Op: mytoken.ADD, X: i, Y: Const{constant.MakeInt64(1)}})
+	f.Emit(&Copy{Dst: i, Src: t1})
+	f.Emit(&Jump{Target: loop})
+	f.Emit(done)
+
+	const want = `i  =  0
+L0
+t0 = i le 10
+if  t0 .false goto  L1
+t1 = i + 1
+i = t1
+goto  L0
+L1
+`
+	var buf bytes.Buffer
+	if err := Fprint(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := f.String(); got != want {
+		t.Errorf("String returns\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package mytoken
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// FloatPrec is the precision, in bits, of the values of FLOAT literals.
+const FloatPrec = 512
+
+// LiteralValue returns the value of lit, a literal of kind INT, FLOAT,
+// CHAR or STRING as the scanner returns it, for instance the Value of
+// an ast.BasicLit:
+//
+//	INT     *big.Int
+//	FLOAT   *big.Float
+//	CHAR    rune
+//	STRING  string
+//
+// All the forms of the Go spec are accepted: prefixes and '_'
+// separators in numbers, escapes in runes and strings, and raw strings.
+// It reports an error if lit is not a valid literal of kind.
+func LiteralValue(kind Token, lit string) (interface{}, error) {
+	switch kind {
+	case INT:
+		// base 0 takes the prefix and the separators into account
+		if x, ok := new(big.Int).SetString(lit, 0); ok {
+			return x, nil
+		}
+	case FLOAT:
+		if x, _, err := big.ParseFloat(lit, 0, FloatPrec, big.ToNearestEven); err == nil {
+			return x, nil
+		}
+	case CHAR:
+		if n := len(lit); n >= 2 && lit[0] == '\'' && lit[n-1] == '\'' {
+			r, _, tail, err := strconv.UnquoteChar(lit[1:n-1], '\'')
+			if err == nil && tail == "" {
+				return r, nil
+			}
+		}
+	case STRING:
+		if s, err := strconv.Unquote(lit); err == nil {
+			return s, nil
+		}
+	default:
+		return nil, fmt.Errorf("%s is not a basic literal", kind)
+	}
+	return nil, fmt.Errorf("invalid %s literal %s", kind, lit)
+}
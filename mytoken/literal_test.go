@@ -0,0 +1,46 @@
+package mytoken
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestLiteralValue(t *testing.T) {
+	for _, test := range []struct {
+		kind Token
+		lit  string
+		want string // the value formatted with %v, or the error
+	}{
+		{INT, "42", "42"},
+		{INT, "1_000", "1000"},
+		{INT, "0x_ff", "255"},
+		{INT, "0o17", "15"},
+		{INT, "017", "15"},
+		{INT, "0b101", "5"},
+		{INT, "123456789012345678901234567890", "123456789012345678901234567890"},
+		{INT, "0x", "invalid int literal 0x"},
+		{FLOAT, "1.5e3", "1500"},
+		{FLOAT, "0x1.8p1", "3"},
+		{FLOAT, ".25", "0.25"},
+		{CHAR, `'a'`, "97"},
+		{CHAR, `'\n'`, "10"},
+		{CHAR, `'é'`, "233"},
+		{CHAR, `'ab'`, "invalid char literal 'ab'"},
+		{STRING, `"a\tb"`, "a\tb"},
+		{STRING, "`a\\tb`", `a\tb`},
+		{STRING, `"a`, `invalid string literal "a`},
+		{IDENT, "a", "identifier is not a basic literal"},
+	} {
+		v, err := LiteralValue(test.kind, test.lit)
+		got := fmt.Sprint(v)
+		if err != nil {
+			got = err.Error()
+		} else if f, ok := v.(*big.Float); ok {
+			got = f.Text('g', 10)
+		}
+		if got != test.want {
+			t.Errorf("%s %s: got %s, want %s", test.kind, test.lit, got, test.want)
+		}
+	}
+}
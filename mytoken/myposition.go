@@ -3,6 +3,7 @@ package mytoken
 import (
 	"fmt"
 	"sort"
+	"sync"
 )
 
 // Position describes an artibitrary source position
@@ -13,7 +14,7 @@ type Position struct {
 	Filename string // filename
 	Offset   int    // offset, starting at 0
 	Line     int    // line number, starting at 1
-	Column   int    // column number, starting at 1
+	Column   int    // column number, starting at 1 (byte count)
 }
 
 // IsValid reports whether the position is valid.
@@ -25,7 +26,9 @@ func (pos *Position) IsValid() bool {
 // String returns a string in one of several forms:
 //
 //	file:line:column    valid position with file name
+//	file:line           valid position with file name but no column (column == 0)
 //	line:column         valid position without file name
+//	line                valid position without file name and no column (column == 0)
 //	file                invalid position with file name
 //	-                   invalid position without file name
 //
@@ -35,7 +38,10 @@ func (pos Position) String() string {
 		if s != "" {
 			s += ":"
 		}
-		s += fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+		s += fmt.Sprintf("%d", pos.Line)
+		if pos.Column != 0 {
+			s += fmt.Sprintf(":%d", pos.Column)
+		}
 	}
 	if s == "" {
 		s = "-"
@@ -131,16 +137,27 @@ func (f *File) SetLinesForContent(content []byte) {
 	f.lines = lines
 }
 
+// A lineInfo is an alternative position for the source from Offset on,
+// as set by a //line directive. A Column of 0 means unknown.
 type lineInfo struct {
 	// fields are exported to make them accessible to gob
-	Offset   int
-	Filename string
-	Line     int
+	Offset       int
+	Filename     string
+	Line, Column int
 }
 
+// AddLineInfo is like AddLineColumnInfo with a column of 1.
 func (f *File) AddLineInfo(offset int, filename string, line int) {
-	if i := len(f.infos); i == 0 || f.infos[i-1].Offset < offset && offset < f.size {
-		f.infos = append(f.infos, lineInfo{offset, filename, line})
+	f.AddLineColumnInfo(offset, filename, line, 1)
+}
+
+// AddLineColumnInfo records that the source from offset on comes from
+// line and column of filename, for the adjusted positions. The offsets
+// must increase from call to call and be smaller than the file size;
+// other calls are ignored.
+func (f *File) AddLineColumnInfo(offset int, filename string, line, column int) {
+	if i := len(f.infos); (i == 0 || f.infos[i-1].Offset < offset) && offset < f.size {
+		f.infos = append(f.infos, lineInfo{offset, filename, line, column})
 	}
 }
 
@@ -177,7 +194,17 @@ func (f *File) unpack(offset int, adjusted bool) (filename string, line, column
 			alt := &f.infos[i]
 			filename = alt.Filename
 			if i := searchInts(f.lines, alt.Offset); i >= 0 {
-				line += alt.Line - i - 1
+				// i+1 is the line on which the alternative position starts
+				d := line - (i + 1)
+				line = alt.Line + d
+				if alt.Column == 0 {
+					// the alternative column is unknown, so are the
+					// columns up to the next line info
+					column = 0
+				} else if d == 0 {
+					// the columns of that line count from its column
+					column = alt.Column + (offset - alt.Offset)
+				}
 			}
 		}
 	}
@@ -205,10 +232,114 @@ func (f *File) Position(p Pos) (pos Position) {
 	return f.PositionFor(p, true)
 }
 
+// Newfile returns a file on its own. Files that share positions, like
+// the files of a package, are added to a FileSet instead.
 func Newfile(name string, base, size int) *File {
 	f := &File{name, base, size, []int{0}, nil}
 	return f
 }
+
+// A FileSet represents a set of source files. Each file of the set has
+// its own range of Pos values, [base, base+size], so that a Pos tells
+// the file it belongs to. Methods of a FileSet may be called
+// concurrently.
+type FileSet struct {
+	mutex sync.RWMutex
+	base  int     // base of the next file
+	files []*File // in increasing order of base
+	last  *File   // cache of the last file looked up
+}
+
+// NewFileSet creates a new file set.
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1} // 0 == NoPos
+}
+
+// Base returns the minimum base the next file added to s may have.
+func (s *FileSet) Base() int {
+	s.mutex.RLock()
+	b := s.base
+	s.mutex.RUnlock()
+	return b
+}
+
+// AddFile adds a new file with the given name, base and size to s and
+// returns it. A negative base stands for s.Base(). The base must not be
+// smaller than s.Base(), and size must not be negative. The file takes
+// the Pos values from base to base+size, the last one for the end of
+// the file, so the next file starts at base+size+1 or later.
+func (s *FileSet) AddFile(filename string, base, size int) *File {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if base < 0 {
+		base = s.base
+	}
+	if base < s.base {
+		panic(fmt.Sprintf("invalid base %d (should be >= %d)", base, s.base))
+	}
+	if size < 0 {
+		panic(fmt.Sprintf("invalid size %d (should be >= 0)", size))
+	}
+	f := Newfile(filename, base, size)
+	s.base = base + size + 1
+	s.files = append(s.files, f)
+	s.last = f
+	return f
+}
+
+// File returns the file of s that contains p, or nil if there is none.
+func (s *FileSet) File(p Pos) *File {
+	if p == NoPos {
+		return nil
+	}
+	s.mutex.RLock()
+	// common case: p is in the last file looked up
+	if f := s.last; f != nil && f.base <= int(p) && int(p) <= f.base+f.size {
+		s.mutex.RUnlock()
+		return f
+	}
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i].base > int(p) }) - 1
+	if i >= 0 {
+		if f := s.files[i]; int(p) <= f.base+f.size {
+			s.mutex.RUnlock()
+			s.mutex.Lock()
+			s.last = f
+			s.mutex.Unlock()
+			return f
+		}
+	}
+	s.mutex.RUnlock()
+	return nil
+}
+
+// PositionFor converts p to a Position in the file of s containing it.
+// If adjusted is set, the position is adjusted by the line information
+// added with AddLineInfo. It returns the zero Position if p is not in a
+// file of s.
+func (s *FileSet) PositionFor(p Pos, adjusted bool) (pos Position) {
+	if f := s.File(p); f != nil {
+		pos = f.PositionFor(p, adjusted)
+	}
+	return
+}
+
+// Position converts p to an adjusted Position, like PositionFor(p, true).
+func (s *FileSet) Position(p Pos) (pos Position) {
+	return s.PositionFor(p, true)
+}
+
+// Iterate calls fn for the files of s in the order they were added,
+// until fn returns false.
+func (s *FileSet) Iterate(fn func(*File) bool) {
+	s.mutex.RLock()
+	files := s.files
+	s.mutex.RUnlock()
+	for _, f := range files {
+		if !fn(f) {
+			break
+		}
+	}
+}
 func searchInts(a []int, x int) int {
 	i, j := 0, len(a)
 	for i < j {
@@ -0,0 +1,65 @@
+package mytoken
+
+import "testing"
+
+func TestFileSet(t *testing.T) {
+	fset := NewFileSet()
+	a := fset.AddFile("a.go", -1, 10)
+	b := fset.AddFile("b.go", -1, 0)
+	c := fset.AddFile("c.go", fset.Base()+5, 20)
+	if a.Base() != 1 || b.Base() != 12 || c.Base() != 18 || fset.Base() != 39 {
+		t.Errorf("got bases %d, %d, %d, next %d; want 1, 12, 18, next 39", a.Base(), b.Base(), c.Base(), fset.Base())
+	}
+	c.AddLine(8)
+
+	for _, test := range []struct {
+		p    Pos
+		file *File
+		pos  string
+	}{
+		{NoPos, nil, "-"},
+		{1, a, "a.go:1:1"},
+		{11, a, "a.go:1:11"}, // end of a
+		{12, b, "b.go:1:1"},
+		{13, nil, "-"},
+		{c.Pos(3), c, "c.go:1:4"},
+		{c.Pos(9), c, "c.go:2:2"},
+		{39, nil, "-"},
+	} {
+		if f := fset.File(test.p); f != test.file {
+			t.Errorf("File(%d): got %v, want %v", test.p, f, test.file)
+		}
+		if pos := fset.Position(test.p).String(); pos != test.pos {
+			t.Errorf("Position(%d): got %s, want %s", test.p, pos, test.pos)
+		}
+	}
+
+	var names []string
+	fset.Iterate(func(f *File) bool {
+		names = append(names, f.Name())
+		return f != b
+	})
+	if len(names) != 2 || names[1] != "b.go" {
+		t.Errorf("Iterate: got %v, want [a.go b.go]", names)
+	}
+}
+
+func TestFileSetAddFilePanics(t *testing.T) {
+	fset := NewFileSet()
+	fset.AddFile("a.go", -1, 10)
+	for _, test := range []struct {
+		base, size int
+	}{
+		{5, 1},   // overlaps a.go
+		{20, -1}, // negative size
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("AddFile(%d, %d) did not panic", test.base, test.size)
+				}
+			}()
+			fset.AddFile("b.go", test.base, test.size)
+		}()
+	}
+}
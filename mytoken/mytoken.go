@@ -176,6 +176,10 @@ var tokens = [...]string{
 	VAR: "var",
 }
 
+// NumTokens is the number of tokens; every token is less than NumTokens.
+//
+const NumTokens = keyword_end
+
 // String returns the string corresponding to the token tok.
 //
 func (tok Token) String() string {
@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"myGo/ast"
+	"myGo/mytoken"
+)
+
+// build creates the syntax tree for a reduction by rule. args holds one
+// value per symbol of rule.pattern: a newToken for terminals, a *badNode
+// for the error terminal, the value of the action for the marker rules of
+// mid-rule actions and the result of an earlier build for nonterminals.
+func build(rule *Rule, args []interface{}) interface{} {
+	switch rule.symbol {
+	case "Program":
+		return &ast.File{Stmts: args[0].([]ast.Stmt)}
+
+	case "StatementList":
+		// the empty statements, with a nil value, are left out
+		var list []ast.Stmt
+		if len(args) > 1 {
+			list = args[0].([]ast.Stmt)
+		}
+		if s, ok := args[len(args)-1].(ast.Stmt); ok {
+			list = append(list, s)
+		}
+		return list
+
+	case "Statement":
+		if bad, ok := args[0].(*badNode); ok {
+			return &ast.BadStmt{From: bad.from, To: bad.to}
+		}
+		if tok, ok := args[0].(newToken); ok {
+			// break, continue
+			return &ast.BranchStmt{TokPos: tok.pos, Tok: tok.tok}
+		}
+		return args[0]
+
+	case "SimpleStmt":
+		if x, ok := args[0].(ast.Expr); ok {
+			return &ast.ExprStmt{X: x}
+		}
+		return args[0]
+
+	case "Declaration":
+		if len(args) == 4 {
+			// identifier := Expression
+			op := args[2].(newToken)
+			return &ast.AssignStmt{
+				Lhs:    []ast.Expr{ident(args[0])},
+				TokPos: op.pos,
+				Tok:    op.tok,
+				Rhs:    []ast.Expr{args[3].(ast.Expr)},
+			}
+		}
+		// Element var: identifier [ Expression ] var
+		x, elt := args[0].(*ast.IndexExpr), args[1].(newToken)
+		name := x.X.(*ast.Ident)
+		typ := &ast.ArrayType{
+			Lbrack: x.Lbrack,
+			Len:    x.Index,
+			Elt:    &ast.Ident{NamePos: elt.pos, Name: elt.lit},
+		}
+		return &ast.DeclStmt{Decl: &ast.GenDecl{
+			TokPos: name.NamePos,
+			Tok:    mytoken.VAR,
+			Specs:  []ast.Spec{&ast.ValueSpec{Names: []*ast.Ident{name}, Type: typ}},
+		}}
+
+	case "Assignment":
+		op := args[1].(newToken)
+		return &ast.AssignStmt{
+			Lhs:    []ast.Expr{args[0].(ast.Expr)},
+			TokPos: op.pos,
+			Tok:    op.tok,
+			Rhs:    []ast.Expr{args[2].(ast.Expr)},
+		}
+
+	case "Block":
+		return &ast.BlockStmt{
+			Lbrace: args[0].(newToken).pos,
+			List:   args[2].([]ast.Stmt),
+			Rbrace: args[3].(newToken).pos,
+		}
+
+	case "IfStmt":
+		s := &ast.IfStmt{
+			If:   args[0].(newToken).pos,
+			Cond: args[1].(ast.Expr),
+			Body: args[3].(*ast.BlockStmt),
+		}
+		if len(args) > 4 {
+			s.Else = args[5].(ast.Stmt)
+		}
+		return s
+
+	case "ForStmt":
+		if len(args) == 3 {
+			// for ForClause Block
+			s := args[1].(*ast.ForStmt)
+			s.For = args[0].(newToken).pos
+			s.Body = args[2].(*ast.BlockStmt)
+			return s
+		}
+		return &ast.ForStmt{
+			For:  args[0].(newToken).pos,
+			Cond: args[1].(ast.Expr),
+			Body: args[3].(*ast.BlockStmt),
+		}
+
+	case "ForClause":
+		return &ast.ForStmt{
+			Init: args[0].(ast.Stmt),
+			Cond: args[2].(ast.Expr),
+			Post: args[4].(ast.Stmt),
+		}
+
+	case "Expression":
+		switch len(args) {
+		case 1:
+			return args[0]
+		case 2:
+			// unary: op PrimaryExpr
+			op := args[0].(newToken)
+			return &ast.UnaryExpr{OpPos: op.pos, Op: op.tok, X: args[1].(ast.Expr)}
+		default:
+			op := args[1].(newToken)
+			return &ast.BinaryExpr{
+				X:     args[0].(ast.Expr),
+				OpPos: op.pos,
+				Op:    op.tok,
+				Y:     args[2].(ast.Expr),
+			}
+		}
+
+	case "PrimaryExpr":
+		if _, ok := args[0].(newToken); ok {
+			return ident(args[0])
+		}
+		return args[0]
+
+	case "IndexableExpr":
+		if len(args) == 2 {
+			x := args[1].(*ast.IndexExpr)
+			x.X = args[0].(ast.Expr)
+			return x
+		}
+		return args[0]
+
+	case "Element":
+		x := args[1].(*ast.IndexExpr)
+		x.X = ident(args[0])
+		return x
+
+	case "Index":
+		// X is filled in by IndexableExpr or Element
+		return &ast.IndexExpr{
+			Lbrack: args[0].(newToken).pos,
+			Index:  expr(args[1]),
+			Rbrack: args[2].(newToken).pos,
+		}
+
+	case "Operand":
+		switch len(args) {
+		case 1:
+			return args[0]
+		default:
+			return &ast.ParenExpr{
+				Lparen: args[0].(newToken).pos,
+				X:      expr(args[1]),
+				Rparen: args[2].(newToken).pos,
+			}
+		}
+
+	case "Literal":
+		return basicLit(args[0])
+	}
+
+	// rules without a node of their own pass their single value up
+	if len(args) == 1 {
+		return args[0]
+	}
+	return nil
+}
+
+// expr returns the expression value v, or a BadExpr if v is the value
+// of the error terminal.
+func expr(v interface{}) ast.Expr {
+	if bad, ok := v.(*badNode); ok {
+		return &ast.BadExpr{Form: bad.from, To: bad.to}
+	}
+	return v.(ast.Expr)
+}
+
+func ident(v interface{}) *ast.Ident {
+	tok := v.(newToken)
+	return &ast.Ident{NamePos: tok.pos, Name: tok.lit}
+}
+
+func basicLit(v interface{}) *ast.BasicLit {
+	tok := v.(newToken)
+	return &ast.BasicLit{ValuePos: tok.pos, Kind: tok.tok, Value: tok.lit}
+}
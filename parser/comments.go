@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"myGo/ast"
+	"myGo/mytoken"
+)
+
+// With ParseComments, the parser groups the comments of a file: the
+// comments of a group follow each other with no token in between, each
+// starting at most one line after the previous one ends. A group that
+// begins a line and ends on the line of the next token or on the line
+// before documents the statement that starts with the token.
+
+// commentState holds the comment groups found so far.
+type commentState struct {
+	groups []*ast.CommentGroup
+	lead   map[mytoken.Pos]*ast.CommentGroup // the groups by the position of the token they document
+
+	group    []*ast.Comment // the current group
+	first    bool           // the current group begins a line
+	endLine  int            // line on which the current group ends
+	prevLine int            // line of the last token
+}
+
+// line returns the line of pos, not adjusted by //line directives, or
+// 0 if the file is unknown.
+func (p *Parser) line(pos mytoken.Pos) int {
+	if p.file == nil {
+		return 0
+	}
+	return p.file.PositionFor(pos, false).Line
+}
+
+// comment records tok, which is at pos and has the literal lit, for
+// grouping the comments.
+func (p *Parser) comment(pos mytoken.Pos, tok mytoken.Token, lit string) {
+	c := &p.comments
+	if tok != mytoken.COMMENT {
+		if len(c.group) > 0 {
+			p.endCommentGroup(pos)
+		}
+		c.prevLine = p.line(pos)
+		return
+	}
+
+	line := p.line(pos)
+	if len(c.group) > 0 && line > c.endLine+1 {
+		p.endCommentGroup(mytoken.NoPos)
+	}
+	if len(c.group) == 0 {
+		c.first = line > c.prevLine
+	}
+	c.group = append(c.group, &ast.Comment{Slash: pos, Text: lit})
+	c.endLine = p.line(pos + mytoken.Pos(len(lit)))
+}
+
+// endCommentGroup ends the current group. next is the position of the
+// token that follows it, or NoPos if a comment follows.
+func (p *Parser) endCommentGroup(next mytoken.Pos) {
+	c := &p.comments
+	g := &ast.CommentGroup{List: c.group}
+	c.groups = append(c.groups, g)
+	if next.IsValid() && c.first && p.line(next) <= c.endLine+1 {
+		if c.lead == nil {
+			c.lead = make(map[mytoken.Pos]*ast.CommentGroup)
+		}
+		c.lead[next] = g
+	}
+	c.group = nil
+}
+
+// attachComments adds the comment groups to f, and the doc comments to
+// the statements they precede.
+func (p *Parser) attachComments(f *ast.File) {
+	f.Comments = p.comments.groups
+	if len(p.comments.lead) == 0 {
+		return
+	}
+	f.Docs = make(map[ast.Stmt]*ast.CommentGroup)
+	var walk func(s ast.Stmt)
+	walk = func(s ast.Stmt) {
+		if s == nil {
+			return
+		}
+		if g := p.comments.lead[s.Pos()]; g != nil {
+			f.Docs[s] = g
+			if d, ok := s.(*ast.DeclStmt); ok {
+				if d, ok := d.Decl.(*ast.GenDecl); ok {
+					d.Doc = g
+				}
+			}
+		}
+		switch s := s.(type) {
+		case *ast.BlockStmt:
+			for _, s := range s.List {
+				walk(s)
+			}
+		case *ast.IfStmt:
+			walk(s.Init)
+			walk(s.Body)
+			walk(s.Else)
+		case *ast.ForStmt:
+			walk(s.Init)
+			walk(s.Post)
+			walk(s.Body)
+		case *ast.LabeledStmt:
+			walk(s.Stmt)
+		}
+	}
+	for _, s := range f.Stmts {
+		walk(s)
+	}
+}
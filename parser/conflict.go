@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConflictKind tells which actions collided in a conflict.
+type ConflictKind int
+
+const (
+	ShiftReduce ConflictKind = iota
+	ReduceReduce
+)
+
+func (k ConflictKind) String() string {
+	if k == ShiftReduce {
+		return "shift/reduce"
+	}
+	return "reduce/reduce"
+}
+
+// A Conflict is a state of the parsing table where more than one action
+// applies to the same lookahead.
+type Conflict struct {
+	Kind       ConflictKind
+	State      int
+	Lookahead  string
+	Items      []string // the items involved, as Rule.Show prints them: "E -> E· + E"
+	Resolution string   // the action chosen: "shift", "reduce E -> E + E" or "error"
+	Resolved   bool     // whether precedence chose the action, not the default
+}
+
+func (c Conflict) String() string {
+	how := "by default"
+	if c.Resolved {
+		how = "by precedence"
+	}
+	s := fmt.Sprintf("state %d: %s conflict on %q, %s %s", c.State, c.Kind, c.Lookahead, c.Resolution, how)
+	for _, item := range c.Items {
+		s += "\n\t" + item
+	}
+	return s
+}
+
+// Options control the construction of a parsing table.
+type Options struct {
+	LALR   bool // build an LALR(1) table instead of a canonical LR(1) one
+	Strict bool // fail if precedence does not resolve every conflict
+}
+
+// Generate builds the parsing table of grammar and reports the conflicts
+// met on the way, ordered by state and lookahead. In strict mode it
+// returns an error if some conflict was resolved by default.
+func Generate(grammar *Grammar, opts Options) (ActionTable, []Conflict, error) {
+	r := &resolver{grammar: grammar}
+	var table ActionTable
+	if opts.LALR {
+		table = computeLALR(grammar, r)
+	} else {
+		table = computeLR1(grammar, r)
+	}
+	sort.SliceStable(r.conflicts, func(i, j int) bool {
+		a, b := r.conflicts[i], r.conflicts[j]
+		if a.State != b.State {
+			return a.State < b.State
+		}
+		return a.Lookahead < b.Lookahead
+	})
+
+	if opts.Strict {
+		var unresolved []string
+		for _, c := range r.conflicts {
+			if !c.Resolved {
+				unresolved = append(unresolved, c.String())
+			}
+		}
+		if len(unresolved) > 0 {
+			return table, r.conflicts, fmt.Errorf("%d unresolved conflicts:\n%s", len(unresolved), strings.Join(unresolved, "\n"))
+		}
+	}
+	return table, r.conflicts, nil
+}
+
+// resolver adds reductions to the rows of a parsing table and records the
+// conflicts it comes across.
+type resolver struct {
+	grammar   *Grammar
+	conflicts []Conflict
+	errors    map[int]SymbolSet // lookaheads made errors by %nonassoc, per state
+}
+
+// reduce adds the reduction by rule on term to actions, the row of state.
+// items are the LR(0) items of the state. A shift/reduce conflict is
+// resolved by the precedence of rule and term if both have one, by
+// shifting otherwise. In a reduce/reduce conflict the rule listed first
+// in the grammar wins.
+func (r *resolver) reduce(state int, actions map[string]Action, items []core, rule *Rule, term string) {
+	if term == "" {
+		// epsilon is not a lookahead
+		return
+	}
+	if r.errors[state].Has(term) {
+		return
+	}
+	old := actions[term]
+	if old == nil {
+		actions[term] = Reduce{rule}
+		return
+	}
+	if old, ok := old.(Reduce); ok && old.rule == rule {
+		return
+	}
+
+	c := Conflict{Kind: ShiftReduce, State: state, Lookahead: term}
+	if _, ok := old.(Reduce); ok {
+		c.Kind = ReduceReduce
+	} else {
+		ruleLevel := r.grammar.RulePrecedence(rule)
+		termLevel, assoc := r.grammar.Precedence(term)
+		if ruleLevel > 0 && termLevel > 0 {
+			c.Resolved = true
+			switch {
+			case ruleLevel > termLevel, ruleLevel == termLevel && assoc == Left:
+				actions[term] = Reduce{rule}
+			case ruleLevel == termLevel && assoc == Nonassoc:
+				delete(actions, term)
+				if r.errors == nil {
+					r.errors = make(map[int]SymbolSet)
+				}
+				if r.errors[state] == nil {
+					r.errors[state] = make(SymbolSet)
+				}
+				r.errors[state].Add(term)
+			}
+		}
+	}
+
+	switch a := actions[term].(type) {
+	case Shift:
+		c.Resolution = "shift"
+	case Reduce:
+		c.Resolution = "reduce " + a.rule.Show("->", -1)
+	default:
+		c.Resolution = "error"
+	}
+	for _, item := range items {
+		sym, end := item.next()
+		involved := !end && sym == term && c.Kind == ShiftReduce
+		if end {
+			involved = item.rule == rule || old == Action(Reduce{item.rule})
+		}
+		if involved {
+			c.Items = append(c.Items, item.rule.Show("->", item.pos))
+		}
+	}
+	r.conflicts = append(r.conflicts, c)
+}
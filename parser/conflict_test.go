@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateShiftReduce(t *testing.T) {
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "S'", pattern: []string{"E"}},
+		{symbol: "E", pattern: []string{"E", "+", "E"}},
+		{symbol: "E", pattern: []string{"a"}},
+	}}
+	g.CollectSymbols()
+	for _, lalr := range []bool{false, true} {
+		_, conflicts, err := Generate(g, Options{LALR: lalr, Strict: true})
+		if err == nil || !strings.Contains(err.Error(), "shift by default") {
+			t.Errorf("LALR=%v: got error %v", lalr, err)
+		}
+		if len(conflicts) != 1 {
+			t.Fatalf("LALR=%v: got %d conflicts, want 1: %v", lalr, len(conflicts), conflicts)
+		}
+		c := conflicts[0]
+		if c.Kind != ShiftReduce || c.Lookahead != "+" || c.Resolved || c.Resolution != "shift" {
+			t.Errorf("LALR=%v: got %v", lalr, c)
+		}
+		want := []string{"E -> E" + middot + " + E", "E -> E + E " + middot}
+		if strings.Join(c.Items, "|") != strings.Join(want, "|") {
+			t.Errorf("LALR=%v: items %q, want %q", lalr, c.Items, want)
+		}
+	}
+}
+
+func TestGeneratePrecedence(t *testing.T) {
+	grammar := func() *Grammar {
+		g := &Grammar{rules: []*Rule{
+			{symbol: "S'", pattern: []string{"E"}},
+			{symbol: "E", pattern: []string{"E", "+", "E"}},
+			{symbol: "E", pattern: []string{"E", "*", "E"}},
+			{symbol: "E", pattern: []string{"E", "<", "E"}},
+			{symbol: "E", pattern: []string{"E", "=", "E"}},
+			{symbol: "E", pattern: []string{"-", "E"}, prec: "u"},
+			{symbol: "E", pattern: []string{"a"}},
+		}}
+		return g.Right("=").Nonassoc("<").Left("+", "-").Left("*").Right("u")
+	}
+	// the resolution of the conflict between the rule with operator
+	// "rule" on the stack and lookahead "term"
+	tests := []struct {
+		rule, term string
+		want       string
+	}{
+		{"+", "+", "reduce"},
+		{"+", "*", "shift"},
+		{"*", "+", "reduce"},
+		{"=", "=", "shift"},
+		{"<", "<", "error"},
+		{"<", "+", "shift"},
+		{"-", "*", "reduce"},
+		{"-", "+", "reduce"},
+	}
+
+	g := grammar()
+	g.CollectSymbols()
+	_, conflicts, err := Generate(g, Options{LALR: true, Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		var rule string
+		if test.rule == "-" {
+			rule = "E -> - E " + middot
+		} else {
+			rule = "E -> E " + test.rule + " E " + middot
+		}
+		found := false
+		for _, c := range conflicts {
+			if c.Lookahead != test.term || !strings.Contains(strings.Join(c.Items, "\n"), rule) {
+				continue
+			}
+			found = true
+			if !strings.HasPrefix(c.Resolution, test.want) || !c.Resolved {
+				t.Errorf("%s on %q: got %v, want %s", rule, test.term, c, test.want)
+			}
+		}
+		if !found {
+			t.Errorf("%s on %q: no conflict", rule, test.term)
+		}
+	}
+
+	// the levels are numbered from the first declaration
+	if level, assoc := g.Precedence("="); level != 1 || assoc != Right {
+		t.Errorf(`Precedence("="): got %d %v`, level, assoc)
+	}
+	if level, _ := g.Precedence("a"); level != 0 {
+		t.Errorf(`Precedence("a"): got %d, want 0`, level)
+	}
+	if level := g.RulePrecedence(g.rules[5]); level != 5 {
+		t.Errorf("RulePrecedence(%s): got %d, want 5 from %%prec", g.rules[5].Show("->", -1), level)
+	}
+}
+
+func TestGenerateReduceReduce(t *testing.T) {
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "S'", pattern: []string{"S"}},
+		{symbol: "S", pattern: []string{"A"}},
+		{symbol: "S", pattern: []string{"B"}},
+		{symbol: "A", pattern: []string{"x"}},
+		{symbol: "B", pattern: []string{"x"}},
+	}}
+	g.CollectSymbols()
+	table, conflicts, err := Generate(g, Options{LALR: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Kind != ReduceReduce || conflicts[0].Resolved {
+		t.Fatalf("got %v, want one unresolved reduce/reduce conflict", conflicts)
+	}
+	if conflicts[0].Resolution != "reduce A -> x" {
+		t.Errorf("resolution %q, want the first rule", conflicts[0].Resolution)
+	}
+	if table == nil {
+		t.Error("no table in non-strict mode")
+	}
+
+	_, _, err = Generate(g, Options{LALR: true, Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "reduce/reduce conflict on \"EOF\"") {
+		t.Errorf("strict mode: got error %v", err)
+	}
+}
+
+func TestGenerateMyGo(t *testing.T) {
+	G.CollectSymbols()
+	_, conflicts, err := Generate(G, Options{LALR: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) == 0 {
+		t.Fatal("no conflicts reported for the myGo grammar")
+	}
+	for _, c := range conflicts {
+		if len(c.Items) < 2 {
+			t.Errorf("conflict without its items: %v", c)
+		}
+	}
+	// precedence resolves all of them
+	for _, c := range conflicts {
+		if !c.Resolved {
+			t.Errorf("unresolved conflict: %v", c)
+		}
+	}
+	if _, _, err := Generate(G, Options{LALR: true, Strict: true}); err != nil {
+		t.Errorf("strict mode rejected the myGo grammar: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ package parser
 import (
 	"fmt"
 	"log"
+	"sort"
 	"unicode"
 )
 
@@ -22,10 +23,16 @@ func (ss SymbolSet) Merge(other SymbolSet) bool {
 	return len(ss) != s
 }
 
+func (ss SymbolSet) copy() SymbolSet {
+	c := make(SymbolSet, len(ss))
+	c.Merge(ss)
+	return c
+}
+
 // SymbolMap will tell us which the symbolset the symbol belong to
 type SymbolMap map[string]SymbolSet
 
-func (sm SymbolMap) Dump(log log.Logger, lable string) {
+func (sm SymbolMap) Dump(log *log.Logger, lable string) {
 	log.Println(lable + ":")
 	for sym, set := range sm {
 		var setStr string
@@ -45,6 +52,16 @@ type Rule struct {
 	symbol string
 	// the parrern of symbols
 	pattern []string
+	// the terminal whose precedence the rule takes, like %prec in yacc;
+	// if empty, the last terminal of the pattern
+	prec string
+	// the name of the semantic action run when the rule is reduced, a key
+	// of FunctionTables; or empty
+	action string
+	// for the marker rule of a mid-rule action, the number of symbols
+	// before the action in the rules that use it; the action receives
+	// their values
+	depth int
 }
 
 func (r *Rule) Show(arrow string, mark int) string {
@@ -61,41 +78,158 @@ func (r *Rule) Show(arrow string, mark int) string {
 	return str
 }
 
-func IsTerminals(symbol string) bool { return len(symbol) == 0 || !unicode.IsUpper((rune(symbol[0]))) }
+// IsTerminals reports whether symbol is a terminal. EOF, the end of
+// input marker, is a terminal too.
+func IsTerminals(symbol string) bool {
+	return len(symbol) == 0 || symbol == "EOF" || !unicode.IsUpper((rune(symbol[0])))
+}
 
 // Grammar is a collection of rules
 type Grammar struct {
-	rules   []*Rule
-	symbols SymbolSet
+	rules      []*Rule
+	symbols    SymbolSet
+	nonterms   SymbolSet   // the symbols with rules
+	tokens     SymbolSet   // terminals declared by name, as %token does
+	precedence []PrecLevel // lowest level first
+}
+
+// Assoc is the associativity of a precedence level.
+type Assoc int
+
+const (
+	Left     Assoc = iota // a - b - c is (a - b) - c
+	Right                 // a = b = c is a = (b = c)
+	Nonassoc              // a < b < c is a syntax error
+)
+
+// PrecLevel declares terminals of equal precedence, like a %left,
+// %right or %nonassoc line in yacc.
+type PrecLevel struct {
+	Assoc Assoc
+	Terms []string
+}
+
+// Left declares terms as left-associative, binding tighter than all
+// the terminals declared before. It returns g.
+func (g *Grammar) Left(terms ...string) *Grammar {
+	g.precedence = append(g.precedence, PrecLevel{Left, terms})
+	return g
+}
+
+// Right declares terms as right-associative, binding tighter than all
+// the terminals declared before. It returns g.
+func (g *Grammar) Right(terms ...string) *Grammar {
+	g.precedence = append(g.precedence, PrecLevel{Right, terms})
+	return g
+}
+
+// Nonassoc declares terms as non-associative, binding tighter than all
+// the terminals declared before. It returns g.
+func (g *Grammar) Nonassoc(terms ...string) *Grammar {
+	g.precedence = append(g.precedence, PrecLevel{Nonassoc, terms})
+	return g
+}
+
+// Precedence returns the precedence level of term and its
+// associativity. Higher levels bind tighter; level 0 means term has no
+// declared precedence.
+func (g *Grammar) Precedence(term string) (level int, assoc Assoc) {
+	for i := len(g.precedence) - 1; i >= 0; i-- {
+		for _, t := range g.precedence[i].Terms {
+			if t == term {
+				return i + 1, g.precedence[i].Assoc
+			}
+		}
+	}
+	return 0, Left
+}
+
+// RulePrecedence returns the precedence level of rule: the one of its
+// %prec terminal, or else of the last terminal in its pattern.
+func (g *Grammar) RulePrecedence(rule *Rule) int {
+	if rule.prec != "" {
+		level, _ := g.Precedence(rule.prec)
+		return level
+	}
+	for i := len(rule.pattern) - 1; i >= 0; i-- {
+		if sym := rule.pattern[i]; sym != "" && g.isTerminal(sym) {
+			level, _ := g.Precedence(sym)
+			return level
+		}
+	}
+	return 0
 }
 
 // CollectSymbols walks all the rules to collect all symbols
 func (g *Grammar) CollectSymbols() {
 	g.symbols = make(SymbolSet)
+	g.nonterms = make(SymbolSet)
 	for _, rule := range g.rules {
 		g.symbols.Add(rule.symbol)
+		g.nonterms.Add(rule.symbol)
 		for _, sym := range rule.pattern {
 			g.symbols.Add(sym)
 		}
 	}
 }
 
+// isTerminal reports whether sym is a terminal of g. Symbols with rules
+// are nonterminals and declared tokens are terminals, whatever their
+// case; IsTerminals decides for the others.
+func (g *Grammar) isTerminal(sym string) bool {
+	switch {
+	case g.nonterms.Has(sym):
+		return false
+	case g.tokens.Has(sym):
+		return true
+	}
+	return IsTerminals(sym)
+}
+
 // GetTerminalsAndNoTerminals
 func (g *Grammar) GetTerminalsAndNoTerminals() (terms []string, noterms []string) {
 	for sym := range g.symbols {
-		if IsTerminals(sym) {
+		if g.isTerminal(sym) {
 			terms = append(terms, sym)
 		} else {
 			noterms = append(noterms, sym)
 		}
 	}
+	sort.Strings(terms)
+	sort.Strings(noterms)
 	return
 }
 
-// First computes the "first" set
+// Nullable returns the nonterminals that derive the empty string.
+func (g *Grammar) Nullable() SymbolSet {
+	nullable := make(SymbolSet)
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.rules {
+			if nullable.Has(rule.symbol) {
+				continue
+			}
+			empty := true
+			for _, sym := range rule.pattern {
+				if sym != "" && !nullable.Has(sym) {
+					empty = false
+					break
+				}
+			}
+			if empty {
+				nullable.Add(rule.symbol)
+				changed = true
+			}
+		}
+	}
+	return nullable
+}
+
+// First computes the "first" set of every symbol. The set of a nullable
+// nonterminal contains "", the empty string.
 func (g *Grammar) First() (first SymbolMap) {
 	g.CollectSymbols()
-	terms, _ := g.GetTerminalsAndNoTerminals()
+	terms, noterms := g.GetTerminalsAndNoTerminals()
 	first = make(SymbolMap)
 
 	// Initialize: terminals point to themself
@@ -103,36 +237,48 @@ func (g *Grammar) First() (first SymbolMap) {
 		first[sym] = make(SymbolSet)
 		first[sym].Add(sym)
 	}
-
-	// Fill with grammars first outputs
-	for _, rule := range g.rules {
-		set := first[rule.symbol]
-		if set == nil {
-			set = make(SymbolSet)
-			first[rule.symbol] = set
-		}
-		set.Add(rule.pattern[0])
+	for _, sym := range noterms {
+		first[sym] = make(SymbolSet)
 	}
 
 	// Iterate until stable
 	for changed := true; changed; {
 		changed = false
-		for _, set := range first {
-			for symbol := range set {
-				if !IsTerminals(symbol) {
-					//set[symbol] = false
-					delete(set, symbol)
-				}
-				if set.Merge(first[symbol]) {
-					changed = true
-				}
+		for _, rule := range g.rules {
+			if first[rule.symbol].Merge(FirstSeq(first, rule.pattern)) {
+				changed = true
 			}
 		}
 	}
 	return
 }
 
-// Follow computes the "follow" set
+// FirstSeq returns the FIRST set of the symbol sequence seq from the
+// FIRST sets of the symbols. It contains "" if all the symbols are
+// nullable, in particular if seq is empty. Symbols without a set are
+// terminals.
+func FirstSeq(first SymbolMap, seq []string) SymbolSet {
+	out := make(SymbolSet)
+	for _, sym := range seq {
+		set, ok := first[sym]
+		if !ok {
+			out.Add(sym)
+			return out
+		}
+		for s := range set {
+			if s != "" {
+				out.Add(s)
+			}
+		}
+		if !set.Has("") {
+			return out
+		}
+	}
+	out.Add("")
+	return out
+}
+
+// Follow computes the "follow" set of every nonterminal
 func (g *Grammar) Follow(first SymbolMap) (follow SymbolMap) {
 	follow = make(SymbolMap)
 	// Initialize Add $ to FOLLOW(S)
@@ -144,7 +290,7 @@ func (g *Grammar) Follow(first SymbolMap) (follow SymbolMap) {
 		changed = false
 		for _, rule := range g.rules {
 			for i, patSym := range rule.pattern {
-				if IsTerminals(patSym) {
+				if g.isTerminal(patSym) {
 					continue
 				}
 				set := follow[patSym]
@@ -152,15 +298,15 @@ func (g *Grammar) Follow(first SymbolMap) (follow SymbolMap) {
 					set = make(SymbolSet)
 					follow[patSym] = set
 				}
-				if i+1 < len(rule.pattern) {
-					nextSym := rule.pattern[i+1]
-					if set.Merge(first[nextSym]) {
-						changed = true
-					}
-				} else {
-					if set.Merge(follow[rule.symbol]) {
-						changed = true
-					}
+				// what comes after patSym in this rule; if that can be
+				// empty, what comes after the rule
+				rest := FirstSeq(first, rule.pattern[i+1:])
+				if rest.Has("") {
+					delete(rest, "")
+					rest.Merge(follow[rule.symbol])
+				}
+				if set.Merge(rest) {
+					changed = true
 				}
 			}
 		}
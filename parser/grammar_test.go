@@ -2,12 +2,13 @@ package parser
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 )
 
 func TestRule_Show(t *testing.T) {
-	var s = Rule{"E", []string{"Term", "+", "Term"}}
+	var s = Rule{symbol: "E", pattern: []string{"Term", "+", "Term"}}
 	str := s.Show("->", 1)
 	t.Logf("%s", str)
 }
@@ -21,8 +22,8 @@ func TestIsTerminals(t *testing.T) {
 }
 
 func TestGrammar_GetTerminalsAndNoTerminals(t *testing.T) {
-	var s = &Rule{"E", []string{"Term", "+", "Term"}}
-	var g = &Grammar{[]*Rule{s}, nil}
+	var s = &Rule{symbol: "E", pattern: []string{"Term", "+", "Term"}}
+	var g = &Grammar{rules: []*Rule{s}}
 	g.CollectSymbols()
 	terminal, noterminal := g.GetTerminalsAndNoTerminals()
 	if strings.Join(terminal, " ") == "+" && strings.Join(noterminal, " ") == "E Term" {
@@ -34,15 +35,15 @@ func TestGrammar_GetTerminalsAndNoTerminals(t *testing.T) {
 
 // The test grammer from P162
 func TestItemSet_Closure(t *testing.T) {
-	var g = &Grammar{[]*Rule{
-		{"S'", []string{"S"}},
-		{"S", []string{"B", "B"}},
-		{"B", []string{"a", "B"}},
-		{"B", []string{"b"}},
-	}, nil}
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "S'", pattern: []string{"S"}},
+		{symbol: "S", pattern: []string{"B", "B"}},
+		{symbol: "B", pattern: []string{"a", "B"}},
+		{symbol: "B", pattern: []string{"b"}},
+	}}
 	g.CollectSymbols()
 	var is = make(ItemSet)
-	is.Add(Item{&Rule{"S", []string{"a", "B"}}, "EOF", 1})
+	is.Add(Item{&Rule{symbol: "S", pattern: []string{"a", "B"}}, "EOF", 1})
 	is.Closure(g)
 	for item := range is {
 		fmt.Printf("%s	%s\n", item.rule.Show("->", item.pos), item.next)
@@ -50,15 +51,15 @@ func TestItemSet_Closure(t *testing.T) {
 }
 
 func TestItemSet_Goto(t *testing.T) {
-	var g = &Grammar{[]*Rule{
-		{"S'", []string{"S"}},
-		{"S", []string{"B", "B"}},
-		{"B", []string{"a", "B"}},
-		{"B", []string{"b"}},
-	}, nil}
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "S'", pattern: []string{"S"}},
+		{symbol: "S", pattern: []string{"B", "B"}},
+		{symbol: "B", pattern: []string{"a", "B"}},
+		{symbol: "B", pattern: []string{"b"}},
+	}}
 	g.CollectSymbols()
 	var is = make(ItemSet)
-	is.Add(Item{&Rule{"S'", []string{"S"}}, "EOF", 0})
+	is.Add(Item{&Rule{symbol: "S'", pattern: []string{"S"}}, "EOF", 0})
 	is.Closure(g)
 	for item := range is {
 		fmt.Printf("%s\t\t%s\n", item.rule.Show("->", item.pos), item.next)
@@ -81,3 +82,157 @@ func TestItemSet_Goto(t *testing.T) {
 	}
 
 }
+
+// show writes the sets of m for the given symbols, each sorted, with ε
+// for the empty string.
+func show(m SymbolMap, syms []string) string {
+	var lines []string
+	for _, sym := range syms {
+		var set []string
+		for s := range m[sym] {
+			if s == "" {
+				s = "ε"
+			}
+			set = append(set, s)
+		}
+		sort.Strings(set)
+		lines = append(lines, sym+": "+strings.Join(set, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestFirstFollow(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []*Rule
+		nullable string
+		first    string // FIRST of the nonterminals, in rule order
+		follow   string
+	}{
+		{
+			// grammar 4.28 of the Dragon book
+			name: "expressions",
+			rules: []*Rule{
+				{symbol: "E", pattern: []string{"T", "E'"}},
+				{symbol: "E'", pattern: []string{"+", "T", "E'"}},
+				{symbol: "E'", pattern: []string{""}},
+				{symbol: "T", pattern: []string{"F", "T'"}},
+				{symbol: "T'", pattern: []string{"*", "F", "T'"}},
+				{symbol: "T'", pattern: []string{""}},
+				{symbol: "F", pattern: []string{"(", "E", ")"}},
+				{symbol: "F", pattern: []string{"id"}},
+			},
+			nullable: "E' T'",
+			first: `E: ( id
+E': + ε
+T: ( id
+T': * ε
+F: ( id`,
+			follow: `E: ) EOF
+E': ) EOF
+T: ) + EOF
+T': ) + EOF
+F: ) * + EOF`,
+		},
+		{
+			// a nullable prefix hides nothing behind it
+			name: "nullable prefix",
+			rules: []*Rule{
+				{symbol: "S", pattern: []string{"A", "B", "c"}},
+				{symbol: "A", pattern: []string{"a"}},
+				{symbol: "A", pattern: []string{""}},
+				{symbol: "B", pattern: []string{"b"}},
+				{symbol: "B", pattern: []string{""}},
+			},
+			nullable: "A B",
+			first: `S: a b c
+A: a ε
+B: b ε`,
+			follow: `S: EOF
+A: b c
+B: c`,
+		},
+		{
+			// marker rules, as in the myGo grammar
+			name: "markers",
+			rules: []*Rule{
+				{symbol: "S", pattern: []string{"for", "E", "For1", "Block"}},
+				{symbol: "For1", pattern: []string{""}},
+				{symbol: "Block", pattern: []string{"{", "NewST", "}", "EndBlock"}},
+				{symbol: "NewST", pattern: []string{""}},
+				{symbol: "EndBlock", pattern: []string{""}},
+				{symbol: "E", pattern: []string{"id", "Id2Operand"}},
+				{symbol: "Id2Operand", pattern: []string{""}},
+			},
+			nullable: "EndBlock For1 Id2Operand NewST",
+			first: `S: for
+For1: ε
+Block: {
+NewST: ε
+EndBlock: ε
+E: id
+Id2Operand: ε`,
+			follow: `S: EOF
+For1: {
+Block: EOF
+NewST: }
+EndBlock: EOF
+E: {
+Id2Operand: {`,
+		},
+	}
+	for _, test := range tests {
+		g := &Grammar{rules: test.rules}
+		var syms []string
+		seen := make(map[string]bool)
+		for _, rule := range test.rules {
+			if !seen[rule.symbol] {
+				seen[rule.symbol] = true
+				syms = append(syms, rule.symbol)
+			}
+		}
+
+		var nullable []string
+		for sym := range g.Nullable() {
+			nullable = append(nullable, sym)
+		}
+		sort.Strings(nullable)
+		if got := strings.Join(nullable, " "); got != test.nullable {
+			t.Errorf("%s: nullable: got %s, want %s", test.name, got, test.nullable)
+		}
+		first := g.First()
+		if got := show(first, syms); got != test.first {
+			t.Errorf("%s: FIRST:\ngot\n%s\nwant\n%s", test.name, got, test.first)
+		}
+		if got := show(g.Follow(first), syms); got != test.follow {
+			t.Errorf("%s: FOLLOW:\ngot\n%s\nwant\n%s", test.name, got, test.follow)
+		}
+	}
+}
+
+func TestFirstSeq(t *testing.T) {
+	g := &Grammar{rules: []*Rule{
+		{symbol: "S", pattern: []string{"A", "B", "c"}},
+		{symbol: "A", pattern: []string{"a"}},
+		{symbol: "A", pattern: []string{""}},
+		{symbol: "B", pattern: []string{"b"}},
+		{symbol: "B", pattern: []string{""}},
+	}}
+	first := g.First()
+	for _, test := range []struct {
+		seq  []string
+		want string
+	}{
+		{nil, "ε"},
+		{[]string{"A"}, "a ε"},
+		{[]string{"A", "B"}, "a b ε"},
+		{[]string{"A", "c", "B"}, "a c"},
+		{[]string{"B", "S"}, "a b c"},
+		{[]string{"EOF"}, "EOF"},
+	} {
+		got := show(SymbolMap{"": FirstSeq(first, test.seq)}, []string{""})
+		if got != ": "+test.want {
+			t.Errorf("FIRST(%v): got %s, want %s", test.seq, got[2:], test.want)
+		}
+	}
+}
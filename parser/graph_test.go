@@ -6,14 +6,14 @@ import (
 )
 
 func TestGraph(t *testing.T) {
-	var g = &Grammar{[]*Rule{
-		{"S'", []string{"S"}},
-		{"S", []string{"a", "A", "d"}},
-		{"S", []string{"b", "A", "c"}},
-		{"S", []string{"a", "e", "c"}},
-		{"S", []string{"b", "e", "d"}},
-		{"A", []string{"e"}},
-	}, nil}
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "S'", pattern: []string{"S"}},
+		{symbol: "S", pattern: []string{"a", "A", "d"}},
+		{symbol: "S", pattern: []string{"b", "A", "c"}},
+		{symbol: "S", pattern: []string{"a", "e", "c"}},
+		{symbol: "S", pattern: []string{"b", "e", "d"}},
+		{symbol: "A", pattern: []string{"e"}},
+	}}
 	g.CollectSymbols()
 	fmt.Println(len(g.symbols))
 	fmt.Println(len(g.First()))
@@ -22,96 +22,96 @@ func TestGraph(t *testing.T) {
 }
 
 func TestGraph3(t *testing.T) {
-	var g = &Grammar{[]*Rule{
-		{"S'", []string{"S"}},
-		{"S", []string{"S", "+", "S"}},
-		{"S", []string{"S", "*", "S"}},
-		{"S", []string{"a"}},
-	}, nil}
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "S'", pattern: []string{"S"}},
+		{symbol: "S", pattern: []string{"S", "+", "S"}},
+		{symbol: "S", pattern: []string{"S", "*", "S"}},
+		{symbol: "S", pattern: []string{"a"}},
+	}}
 	g.CollectSymbols()
 	act := ComputeActions(g)
 	act.Dump()
 }
 
 func TestGraph2(t *testing.T) {
-	var g = &Grammar{[]*Rule{
+	var g = &Grammar{rules: []*Rule{
 		// Grammer start:
-		{"Program", []string{"StatementList"}},
+		{symbol: "Program", pattern: []string{"StatementList"}},
 		// for
-		{"ForStmt", []string{"for", "Expression", "Block"}},
-		{"ForStmt", []string{"for", "ForClause", "Block"}},
-		{"ForClause", []string{"SimpleStmt", ";", "Expression", ";", "SimpleStmt"}},
+		{symbol: "ForStmt", pattern: []string{"for", "Expression", "Block"}},
+		{symbol: "ForStmt", pattern: []string{"for", "ForClause", "Block"}},
+		{symbol: "ForClause", pattern: []string{"SimpleStmt", ";", "Expression", ";", "SimpleStmt"}},
 		// if
-		{"IfStmt", []string{"if", "Expression", "Block"}},
-		{"IfStmt", []string{"if", "Expression", "Block", "else", "IfStmt"}},
-		{"IfStmt", []string{"if", "Expression", "Block", "else", "Block"}},
+		{symbol: "IfStmt", pattern: []string{"if", "Expression", "Block"}},
+		{symbol: "IfStmt", pattern: []string{"if", "Expression", "Block", "else", "IfStmt"}},
+		{symbol: "IfStmt", pattern: []string{"if", "Expression", "Block", "else", "Block"}},
 		// assignment
-		{"Assignment", []string{"Expression", "=", "Expression"}},
+		{symbol: "Assignment", pattern: []string{"Expression", "=", "Expression"}},
 		// 语句
-		{"Statement", []string{"Declaration"}},
-		{"Statement", []string{"SimpleStmt"}},
-		{"Statement", []string{"break"}},
-		{"Statement", []string{"continue"}},
-		{"Statement", []string{"Block"}},
-		{"Statement", []string{"IfStmt"}},
-		{"Statement", []string{"ForStmt"}},
-		{"SimpleStmt", []string{"Expression"}},
-		{"SimpleStmt", []string{"Assignment"}},
+		{symbol: "Statement", pattern: []string{"Declaration"}},
+		{symbol: "Statement", pattern: []string{"SimpleStmt"}},
+		{symbol: "Statement", pattern: []string{"break"}},
+		{symbol: "Statement", pattern: []string{"continue"}},
+		{symbol: "Statement", pattern: []string{"Block"}},
+		{symbol: "Statement", pattern: []string{"IfStmt"}},
+		{symbol: "Statement", pattern: []string{"ForStmt"}},
+		{symbol: "SimpleStmt", pattern: []string{"Expression"}},
+		{symbol: "SimpleStmt", pattern: []string{"Assignment"}},
 		// 表达式
-		{"Expression", []string{"UnaryExpr"}},
-		{"Expression", []string{"Expression", "BinaryOp", "Expression"}},
-		{"BinaryOp", []string{"||"}},
-		{"BinaryOp", []string{"&&"}},
-		{"BinaryOp", []string{"=="}},
-		{"BinaryOp", []string{"!="}},
-		{"BinaryOp", []string{">"}},
-		{"BinaryOp", []string{"<"}},
-		{"BinaryOp", []string{"+"}},
-		{"BinaryOp", []string{"-"}},
-		{"BinaryOp", []string{"*"}},
-		{"BinaryOp", []string{"/"}},
-		{"UnaryExpr", []string{"PrimaryExpr"}},
-		{"UnaryExpr", []string{"UnaryOP", "UnaryExpr"}},
-		{"UnaryOP", []string{"+"}},
-		{"UnaryOP", []string{"-"}},
-		{"UnaryOP", []string{"!"}},
-		{"PrimaryExpr", []string{"Operand"}},
-		{"PrimaryExpr", []string{"PrimaryExpr", "Index"}},
-		{"Index", []string{"[", "Expression", "]"}},
+		{symbol: "Expression", pattern: []string{"UnaryExpr"}},
+		{symbol: "Expression", pattern: []string{"Expression", "BinaryOp", "Expression"}},
+		{symbol: "BinaryOp", pattern: []string{"||"}},
+		{symbol: "BinaryOp", pattern: []string{"&&"}},
+		{symbol: "BinaryOp", pattern: []string{"=="}},
+		{symbol: "BinaryOp", pattern: []string{"!="}},
+		{symbol: "BinaryOp", pattern: []string{">"}},
+		{symbol: "BinaryOp", pattern: []string{"<"}},
+		{symbol: "BinaryOp", pattern: []string{"+"}},
+		{symbol: "BinaryOp", pattern: []string{"-"}},
+		{symbol: "BinaryOp", pattern: []string{"*"}},
+		{symbol: "BinaryOp", pattern: []string{"/"}},
+		{symbol: "UnaryExpr", pattern: []string{"PrimaryExpr"}},
+		{symbol: "UnaryExpr", pattern: []string{"UnaryOP", "UnaryExpr"}},
+		{symbol: "UnaryOP", pattern: []string{"+"}},
+		{symbol: "UnaryOP", pattern: []string{"-"}},
+		{symbol: "UnaryOP", pattern: []string{"!"}},
+		{symbol: "PrimaryExpr", pattern: []string{"Operand"}},
+		{symbol: "PrimaryExpr", pattern: []string{"PrimaryExpr", "Index"}},
+		{symbol: "Index", pattern: []string{"[", "Expression", "]"}},
 		// Operand
-		{"Operand", []string{"Literal"}},
-		{"Operand", []string{"identifier"}},
-		{"Operand", []string{"(", "Expression", ")"}},
-		{"Literal", []string{"int"}},
-		{"Literal", []string{"float"}},
+		{symbol: "Operand", pattern: []string{"Literal"}},
+		{symbol: "Operand", pattern: []string{"identifier"}},
+		{symbol: "Operand", pattern: []string{"(", "Expression", ")"}},
+		{symbol: "Literal", pattern: []string{"int"}},
+		{symbol: "Literal", pattern: []string{"float"}},
 		// 声明
-		{"Declaration", []string{"identifier", ":=", "Expression"}},
-		{"Declaration", []string{"identifier", "Type", "=", "Expression"}},
+		{symbol: "Declaration", pattern: []string{"identifier", ":=", "Expression"}},
+		{symbol: "Declaration", pattern: []string{"identifier", "Type", "=", "Expression"}},
 		// Blocks
-		{"Block", []string{"{", "StatementList", "}"}},
+		{symbol: "Block", pattern: []string{"{", "StatementList", "}"}},
 
-		{"StatementList", []string{"Statement", "StatementList"}},
-		{"StatementList", []string{"Statement"}},
+		{symbol: "StatementList", pattern: []string{"Statement", "StatementList"}},
+		{symbol: "StatementList", pattern: []string{"Statement"}},
 		// 类型
-		{"Type", []string{"[", "int", "]", "var"}},
-	}, nil}
+		{symbol: "Type", pattern: []string{"[", "int", "]", "var"}},
+	}}
 	g.CollectSymbols()
 	actionTable := ComputeActions(g)
 	Graph(g, actionTable)
 }
 
 func TestGraph4(t *testing.T) {
-	var g = &Grammar{[]*Rule{
-		{"Program", []string{"SL"}},
-		{"SL", []string{"ST", "SL"}},
-		{"SL", []string{"ST"}},
-		{"ST", []string{"st"}},
-		{"ST", []string{"if"}},
-		{"ST", []string{"for"}},
-		{"ST", []string{"for2"}},
-		{"ST", []string{"Block"}},
-		{"Block", []string{"{", "SL", "}"}},
-	}, nil}
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "Program", pattern: []string{"SL"}},
+		{symbol: "SL", pattern: []string{"ST", "SL"}},
+		{symbol: "SL", pattern: []string{"ST"}},
+		{symbol: "ST", pattern: []string{"st"}},
+		{symbol: "ST", pattern: []string{"if"}},
+		{symbol: "ST", pattern: []string{"for"}},
+		{symbol: "ST", pattern: []string{"for2"}},
+		{symbol: "ST", pattern: []string{"Block"}},
+		{symbol: "Block", pattern: []string{"{", "SL", "}"}},
+	}}
 	g.CollectSymbols()
 	actionTable := ComputeActions(g)
 	Graph(g, actionTable)
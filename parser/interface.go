@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"io"
+	"myGo/ast"
+	"myGo/mytoken"
+)
+
+// A Mode value is a set of flags (or 0). They control optional parser
+// functionality.
+type Mode uint
+
+const (
+	Trace         Mode = 1 << iota // print the reductions as they happen
+	ParseComments                  // parse comments and add them to the syntax tree
+	CheckTypes                     // type-check the program and report the type errors
+)
+
+// A TokenSource supplies the tokens of a file to the parser. Scan
+// returns the position, the token and its literal, as scanner.Scanner
+// does; it returns EOF at the end of the input. COMMENT tokens are
+// skipped by the parser, unless the mode is ParseComments.
+type TokenSource interface {
+	Scan() (pos mytoken.Pos, tok mytoken.Token, lit string)
+}
+
+// SetMode sets the mode of p. It must be called before parsing starts.
+func (p *Parser) SetMode(mode Mode) {
+	p.mode = mode
+}
+
+// ParseFile parses src, the content of file, as a myGo program and
+// returns its syntax tree. The three-address code generated by the
+// semantic actions is discarded; the semantic errors are reported as
+// the syntax errors are.
+//
+// If there are errors, the returned syntax tree holds BadStmt and
+// BadExpr nodes where the input could not be parsed, and the error is a
+// scanner.ErrorList sorted by position, with the first error of each
+// line. The tree is nil if the parser could not recover.
+func ParseFile(file *mytoken.File, src []byte, mode Mode) (*ast.File, error) {
+	p := NewTableParser(MyGo)
+	p.SetMode(mode)
+	p.SetOutput(io.Discard)
+	return p.Parse(file, src, "Program")
+}
+
+// ParseExpr parses the expression x and returns its syntax tree. The
+// positions in the tree are offsets in x, plus one.
+func ParseExpr(x string) (ast.Expr, error) {
+	src := []byte(x)
+	file := mytoken.Newfile("", 1, len(src))
+	p := NewTableParser(MyGo)
+	p.SetOutput(io.Discard)
+	f, err := p.Parse(file, src, "Program")
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range f.Stmts {
+		if _, ok := s.(*ast.ExprStmt); !ok || i > 0 {
+			p.error(s.Pos(), "expected expression")
+			return nil, p.err()
+		}
+	}
+	if len(f.Stmts) == 0 {
+		p.error(file.Pos(len(src)), "expected expression")
+		return nil, p.err()
+	}
+	return f.Stmts[0].(*ast.ExprStmt).X, nil
+}
@@ -0,0 +1,277 @@
+package parser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LALR(1) construction
+//
+// The LR(0) automaton is built first, states are identified by their
+// kernel items only, so states with identical cores are never split.
+// The lookaheads of the kernel items are then computed by spontaneous
+// generation and propagation (Dragon book, algorithm 4.63): the closure
+// of every kernel item with the dummy lookahead "#" tells which
+// lookaheads arise in the successor states on their own and which are
+// passed on from the kernel item.
+
+// propagated is the dummy lookahead used to detect propagation.
+const propagated = "#"
+
+// core is an LR(0) item: a rule with a position in its pattern.
+type core struct {
+	rule *Rule
+	pos  int
+}
+
+func (c core) next() (sym string, end bool) {
+	return Item{c.rule, "", c.pos}.NextSym()
+}
+
+// lalrState is a state of the LR(0) automaton.
+type lalrState struct {
+	kernel []core
+	la     map[core]SymbolSet    // lookaheads of the kernel items
+	goTo   map[string]int        // transitions
+	prop   map[core][]lalrTarget // where the lookaheads of a kernel item propagate
+}
+
+type lalrTarget struct {
+	state int
+	item  core
+}
+
+type lalrBuilder struct {
+	grammar *Grammar
+	first   SymbolMap
+	symbols []string // grammar symbols in a fixed order
+	ruleIds map[*Rule]int
+	states  []*lalrState
+	index   map[string]int // kernel key -> state
+}
+
+// ComputeLALR builds the parsing table of grammar with the LALR(1)
+// method. The table has as many states as the LR(0) automaton, which
+// is usually far fewer than ComputeActions builds for canonical LR(1).
+func ComputeLALR(grammar *Grammar) ActionTable {
+	table, _, _ := Generate(grammar, Options{LALR: true})
+	return table
+}
+
+func computeLALR(grammar *Grammar, r *resolver) ActionTable {
+	b := &lalrBuilder{
+		grammar: grammar,
+		first:   grammar.First(),
+		ruleIds: make(map[*Rule]int),
+		index:   make(map[string]int),
+	}
+	for sym := range grammar.symbols {
+		if sym != "" {
+			b.symbols = append(b.symbols, sym)
+		}
+	}
+	sort.Strings(b.symbols)
+	for i, rule := range grammar.rules {
+		b.ruleIds[rule] = i
+	}
+
+	b.automaton()
+	b.lookaheads()
+	return b.table(r)
+}
+
+// key identifies a state by its kernel.
+func (b *lalrBuilder) key(kernel []core) string {
+	ids := make([]string, len(kernel))
+	for i, c := range kernel {
+		ids[i] = strconv.Itoa(b.ruleIds[c.rule]) + "." + strconv.Itoa(c.pos)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, " ")
+}
+
+func (b *lalrBuilder) addState(kernel []core) int {
+	k := b.key(kernel)
+	if id, ok := b.index[k]; ok {
+		return id
+	}
+	id := len(b.states)
+	b.states = append(b.states, &lalrState{
+		kernel: kernel,
+		la:     make(map[core]SymbolSet),
+		goTo:   make(map[string]int),
+		prop:   make(map[core][]lalrTarget),
+	})
+	b.index[k] = id
+	return id
+}
+
+// closure0 returns the LR(0) closure of kernel.
+func (b *lalrBuilder) closure0(kernel []core) []core {
+	items := append([]core(nil), kernel...)
+	added := make(map[string]bool)
+	for i := 0; i < len(items); i++ {
+		sym, end := items[i].next()
+		if end || b.grammar.isTerminal(sym) || added[sym] {
+			continue
+		}
+		added[sym] = true
+		for _, rule := range b.grammar.rules {
+			if rule.symbol == sym {
+				items = append(items, core{rule, 0})
+			}
+		}
+	}
+	return items
+}
+
+// automaton builds the LR(0) states and their transitions.
+func (b *lalrBuilder) automaton() {
+	b.addState([]core{{b.grammar.rules[0], 0}})
+	for i := 0; i < len(b.states); i++ {
+		items := b.closure0(b.states[i].kernel)
+		for _, sym := range b.symbols {
+			var kernel []core
+			for _, c := range items {
+				if next, end := c.next(); !end && next == sym {
+					kernel = append(kernel, core{c.rule, c.pos + 1})
+				}
+			}
+			if kernel != nil {
+				b.states[i].goTo[sym] = b.addState(kernel)
+			}
+		}
+	}
+}
+
+// closure1 returns the LR(1) closure of the items, each core with a set
+// of lookaheads.
+func (b *lalrBuilder) closure1(items map[core]SymbolSet) map[core]SymbolSet {
+	for changed := true; changed; {
+		changed = false
+		for c, la := range items {
+			sym, end := c.next()
+			if end || b.grammar.isTerminal(sym) {
+				continue
+			}
+			follow := firstSeq(b.first, c.rule.pattern[c.pos+1:], la)
+			for _, rule := range b.grammar.rules {
+				if rule.symbol != sym {
+					continue
+				}
+				nc := core{rule, 0}
+				set := items[nc]
+				if set == nil {
+					set = make(SymbolSet)
+					items[nc] = set
+					changed = true
+				}
+				if set.Merge(follow) {
+					changed = true
+				}
+			}
+		}
+	}
+	return items
+}
+
+// lookaheads determines the lookaheads of all kernel items.
+func (b *lalrBuilder) lookaheads() {
+	for _, st := range b.states {
+		for _, k := range st.kernel {
+			st.la[k] = make(SymbolSet)
+		}
+	}
+	b.states[0].la[b.states[0].kernel[0]].Add("EOF")
+
+	// spontaneous lookaheads and propagation links
+	for _, st := range b.states {
+		for _, k := range st.kernel {
+			dummy := SymbolSet{propagated: true}
+			for c, la := range b.closure1(map[core]SymbolSet{k: dummy}) {
+				sym, end := c.next()
+				if end {
+					continue
+				}
+				target := lalrTarget{st.goTo[sym], core{c.rule, c.pos + 1}}
+				for a := range la {
+					if a == propagated {
+						st.prop[k] = append(st.prop[k], target)
+					} else {
+						b.states[target.state].la[target.item].Add(a)
+					}
+				}
+			}
+		}
+	}
+
+	// propagate until nothing changes
+	for changed := true; changed; {
+		changed = false
+		for _, st := range b.states {
+			for k, targets := range st.prop {
+				for _, t := range targets {
+					if b.states[t.state].la[t.item].Merge(st.la[k]) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// table fills the action table: shifts and gotos from the transitions,
+// reductions from the complete items of each state's closure.
+func (b *lalrBuilder) table(r *resolver) ActionTable {
+	allActions := make(ActionTable, len(b.states))
+	for i, st := range b.states {
+		actions := make(map[string]Action)
+		allActions[i] = actions
+		for sym, target := range st.goTo {
+			actions[sym] = Shift{target}
+		}
+
+		la := make(map[core]SymbolSet)
+		for k, set := range st.la {
+			la[k] = set.copy()
+		}
+		b.closure1(la)
+		items := make([]core, 0, len(la))
+		for c := range la {
+			items = append(items, c)
+		}
+		// resolve conflicts in rule order, as the grammar lists them
+		sort.Slice(items, func(i, j int) bool {
+			if a, b := b.ruleIds[items[i].rule], b.ruleIds[items[j].rule]; a != b {
+				return a < b
+			}
+			return items[i].pos < items[j].pos
+		})
+		for _, c := range items {
+			if _, end := c.next(); !end {
+				continue
+			}
+			terms := make([]string, 0, len(la[c]))
+			for term := range la[c] {
+				terms = append(terms, term)
+			}
+			sort.Strings(terms)
+			for _, term := range terms {
+				r.reduce(i, actions, items, c.rule, term)
+			}
+		}
+	}
+	return allActions
+}
+
+// firstSeq returns the FIRST set of the symbol sequence seq followed by
+// any of the lookaheads la.
+func firstSeq(first SymbolMap, seq []string, la SymbolSet) SymbolSet {
+	out := FirstSeq(first, seq)
+	if out.Has("") {
+		delete(out, "")
+		out.Merge(la)
+	}
+	return out
+}
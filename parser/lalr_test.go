@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"myGo/mytoken"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// The grammar 4.55 from the Dragon book
+func TestComputeLALR(t *testing.T) {
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "S'", pattern: []string{"S"}},
+		{symbol: "S", pattern: []string{"C", "C"}},
+		{symbol: "C", pattern: []string{"c", "C"}},
+		{symbol: "C", pattern: []string{"d"}},
+	}}
+	g.CollectSymbols()
+	if n := len(ComputeActions(g)); n != 10 {
+		t.Errorf("canonical LR(1): got %d states, want 10", n)
+	}
+	act := ComputeLALR(g)
+	if n := len(act); n != 7 {
+		t.Fatalf("LALR(1): got %d states, want 7", n)
+	}
+
+	// the states of C -> c · C, C -> d · and C -> c C · are merged, so
+	// their reductions are on the lookaheads of both LR(1) states
+	want := []string{
+		"C: goto 1, S: goto 2, c: shift 3, d: shift 4",
+		"C: goto 5, c: shift 3, d: shift 4",
+		"EOF: reduce S' -> S",
+		"C: goto 6, c: shift 3, d: shift 4",
+		"EOF: reduce C -> d, c: reduce C -> d, d: reduce C -> d",
+		"EOF: reduce S -> C C",
+		"EOF: reduce C -> c C, c: reduce C -> c C, d: reduce C -> c C",
+	}
+	for state, row := range act {
+		var entries []string
+		for sym, a := range row {
+			switch a := a.(type) {
+			case Shift:
+				if IsTerminals(sym) {
+					entries = append(entries, fmt.Sprintf("%s: shift %d", sym, a.state))
+				} else {
+					entries = append(entries, fmt.Sprintf("%s: goto %d", sym, a.state))
+				}
+			case Reduce:
+				entries = append(entries, fmt.Sprintf("%s: reduce %s", sym, a.rule.Show("->", -1)))
+			}
+		}
+		sort.Strings(entries)
+		if got := strings.Join(entries, ", "); got != want[state] {
+			t.Errorf("state %d: got %s, want %s", state, got, want[state])
+		}
+	}
+}
+
+func TestComputeLALRMyGo(t *testing.T) {
+	src := []byte(`i := -1
+	j := 2
+	for i < 10 {
+		if j > i && i > 0 {
+			k := (i + j) * 2
+			j = k / 2 - 1
+		} else {
+			i = i + 1
+		}
+	}
+	`)
+	G.CollectSymbols()
+	parse := func(ac ActionTable) string {
+		var out bytes.Buffer
+		p := NewParser(ac)
+		p.SetOutput(&out)
+		if _, err := p.Parse(mytoken.Newfile("", 1, len(src)), src, "Program"); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+	lalr := ComputeLALR(G)
+	lr1 := ComputeActions(G)
+	t.Logf("LALR(1): %d states, LR(1): %d states", len(lalr), len(lr1))
+	if got, want := parse(lalr), parse(lr1); got != want {
+		t.Errorf("LALR(1) table generates\n%s\nLR(1) table generates\n%s", got, want)
+	}
+}
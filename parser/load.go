@@ -0,0 +1,323 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"myGo/mytoken"
+	"myGo/scanner"
+	"strconv"
+	text "text/scanner"
+)
+
+// Grammar files
+//
+// A grammar file has a declaration section and a rule section, separated
+// by %%, as in yacc:
+//
+//	// comments run to the end of the line, /* or are enclosed */
+//	%token identifier int
+//	%left "+" "-"
+//	%left "*" "/"
+//	%%
+//	Expr
+//		: Expr "+" Expr {AddExpr}
+//		| Expr "*" Expr {MulExpr}
+//		| "-" Expr %prec "*"
+//		| identifier
+//		|
+//		;
+//
+// %token declares named terminals. Quoted symbols are terminals too and
+// need no declaration; error and EOF are predeclared. Every other symbol
+// must have rules. %left, %right and %nonassoc declare a precedence level
+// each, the first one binding loosest. %prec gives an alternative the
+// precedence of a terminal. An empty alternative matches the empty
+// string. The first rule is the start rule.
+//
+// A named semantic action {Name} at the end of an alternative is run
+// when the rule is reduced. An action elsewhere in an alternative is a
+// mid-rule action: it stands for a marker rule @Name -> ε, which runs the
+// action with the values of the symbols before it. A mid-rule action must
+// follow the same number of symbols wherever it is used.
+
+// LoadGrammar reads a grammar file from r. It reports the first error
+// in the file with its position.
+func LoadGrammar(r io.Reader) (*Grammar, error) {
+	l := &loader{
+		g:       &Grammar{tokens: make(SymbolSet)},
+		prec:    make(map[string]bool),
+		markers: make(map[string]*Rule),
+	}
+	l.s.Init(r)
+	l.s.Mode = text.ScanIdents | text.ScanStrings | text.ScanComments | text.SkipComments
+	l.s.Error = func(s *text.Scanner, msg string) { l.error(l.position(s.Pos()), msg) }
+	l.next()
+
+	l.declarations()
+	l.rules()
+	if len(l.errors) == 0 {
+		l.check()
+	}
+	if len(l.errors) > 0 {
+		return nil, l.errors[0]
+	}
+	l.g.CollectSymbols()
+	return l.g, nil
+}
+
+// loader holds the state of LoadGrammar.
+type loader struct {
+	s   text.Scanner
+	tok rune   // current token
+	lit string // its text, unquoted for strings
+	pos mytoken.Position
+
+	g       *Grammar
+	errors  scanner.ErrorList
+	prec    map[string]bool  // terminals with a precedence
+	markers map[string]*Rule // marker rules of the mid-rule actions
+	uses    []use            // symbols to check once all rules are known
+}
+
+// use is an unquoted symbol in a rule or a declaration.
+type use struct {
+	name string
+	pos  mytoken.Position
+}
+
+func (l *loader) position(p text.Position) mytoken.Position {
+	return mytoken.Position{Filename: p.Filename, Offset: p.Offset, Line: p.Line, Column: p.Column}
+}
+
+func (l *loader) error(pos mytoken.Position, msg string) {
+	l.errors.Add(pos, msg)
+}
+
+func (l *loader) next() {
+	l.tok = l.s.Scan()
+	l.pos = l.position(l.s.Position)
+	l.lit = l.s.TokenText()
+	if l.tok == text.String {
+		if s, err := strconv.Unquote(l.lit); err == nil {
+			l.lit = s
+		}
+	}
+}
+
+// found describes the current token for error messages.
+func (l *loader) found() string {
+	switch l.tok {
+	case text.EOF:
+		return "end of file"
+	case text.Ident:
+		return l.lit
+	}
+	return strconv.Quote(l.lit)
+}
+
+func (l *loader) expect(tok rune, what string) bool {
+	if l.tok != tok {
+		l.error(l.pos, fmt.Sprintf("expected %s, found %s", what, l.found()))
+		return false
+	}
+	l.next()
+	return true
+}
+
+// directive reads a %word and returns the word.
+func (l *loader) directive() string {
+	pos := l.pos
+	l.next()
+	if l.tok == '%' && l.pos.Offset == pos.Offset+1 {
+		l.next()
+		return "%"
+	}
+	if l.tok != text.Ident || l.pos.Offset != pos.Offset+1 {
+		l.error(pos, "expected directive after %")
+		return ""
+	}
+	word := l.lit
+	l.next()
+	return word
+}
+
+// symbol reads a grammar symbol, a name or a quoted terminal.
+func (l *loader) symbol() (string, bool) {
+	switch l.tok {
+	case text.Ident:
+		name := l.lit
+		l.uses = append(l.uses, use{name, l.pos})
+		l.next()
+		return name, true
+	case text.String:
+		name := l.lit
+		if name == "" {
+			l.error(l.pos, "empty terminal")
+		}
+		l.next()
+		return name, true
+	}
+	return "", false
+}
+
+func (l *loader) declarations() {
+	for l.tok == '%' {
+		pos := l.pos
+		var assoc Assoc
+		switch l.directive() {
+		case "%":
+			return
+		case "token":
+			for l.tok == text.Ident {
+				l.g.tokens[l.lit] = true
+				l.next()
+			}
+			continue
+		case "left":
+			assoc = Left
+		case "right":
+			assoc = Right
+		case "nonassoc":
+			assoc = Nonassoc
+		case "":
+			return
+		default:
+			l.error(pos, "unknown directive")
+			return
+		}
+
+		var terms []string
+		for {
+			p := l.pos
+			term, ok := l.symbol()
+			if !ok {
+				break
+			}
+			if l.prec[term] {
+				l.error(p, "precedence of "+term+" declared twice")
+			}
+			l.prec[term] = true
+			terms = append(terms, term)
+		}
+		if len(terms) == 0 {
+			l.error(pos, "no terminals in precedence declaration")
+		}
+		l.g.precedence = append(l.g.precedence, PrecLevel{assoc, terms})
+	}
+	l.error(l.pos, fmt.Sprintf("expected %%%%, found %s", l.found()))
+}
+
+func (l *loader) rules() {
+	for l.tok != text.EOF {
+		if l.tok != text.Ident {
+			l.error(l.pos, fmt.Sprintf("expected rule name, found %s", l.found()))
+			return
+		}
+		symbol := l.lit
+		l.next()
+		if !l.expect(':', `":"`) {
+			return
+		}
+		for {
+			if !l.alternative(symbol) {
+				return
+			}
+			if l.tok != '|' {
+				break
+			}
+			l.next()
+		}
+		if !l.expect(';', `"|" or ";"`) {
+			return
+		}
+	}
+	if len(l.g.rules) == 0 {
+		l.error(l.pos, "no rules")
+	}
+}
+
+// alternative reads the pattern of a rule for symbol. It reports
+// whether it succeeded.
+func (l *loader) alternative(symbol string) bool {
+	rule := &Rule{symbol: symbol}
+	l.g.rules = append(l.g.rules, rule)
+	var markers []*Rule
+	// an action is a mid-rule action if anything follows it
+	var name string
+	var pos mytoken.Position
+	mid := func() {
+		if name == "" {
+			return
+		}
+		marker := l.markers[name]
+		switch {
+		case marker == nil:
+			marker = &Rule{symbol: "@" + name, pattern: []string{""}, action: name, depth: len(rule.pattern)}
+			l.markers[name] = marker
+			markers = append(markers, marker)
+		case marker.depth != len(rule.pattern):
+			l.error(pos, fmt.Sprintf("action %s follows %d symbols here and %d elsewhere", name, len(rule.pattern), marker.depth))
+		}
+		rule.pattern = append(rule.pattern, marker.symbol)
+		name = ""
+	}
+	for {
+		if l.tok == text.Ident || l.tok == text.String {
+			mid()
+			sym, _ := l.symbol()
+			rule.pattern = append(rule.pattern, sym)
+			continue
+		}
+		if l.tok != '{' {
+			break
+		}
+		mid()
+		l.next()
+		pos, name = l.pos, l.lit
+		if !l.expect(text.Ident, "action name") || !l.expect('}', `"}"`) {
+			return false
+		}
+	}
+	rule.action = name
+	if l.tok == '%' {
+		pos := l.pos
+		if l.directive() != "prec" {
+			l.error(pos, "expected %prec")
+			return false
+		}
+		p := l.pos
+		term, ok := l.symbol()
+		if !ok {
+			l.error(l.pos, fmt.Sprintf("expected terminal, found %s", l.found()))
+			return false
+		}
+		if !l.prec[term] {
+			l.error(p, "no precedence declared for "+term)
+		}
+		rule.prec = term
+	}
+	if len(rule.pattern) == 0 {
+		rule.pattern = []string{""}
+	}
+	// the markers follow the first rule that uses them
+	l.g.rules = append(l.g.rules, markers...)
+	return true
+}
+
+// check reports the undefined symbols.
+func (l *loader) check() {
+	nonterms := make(map[string]bool)
+	for _, rule := range l.g.rules {
+		nonterms[rule.symbol] = true
+	}
+	for _, u := range l.uses {
+		switch {
+		case l.g.tokens[u.name] && nonterms[u.name]:
+			l.error(u.pos, u.name+" is declared as a token and has rules")
+		case nonterms[u.name], l.g.tokens[u.name]:
+		case u.name == errorSym, u.name == "EOF":
+		default:
+			l.error(u.pos, "undefined: "+u.name)
+		}
+	}
+}
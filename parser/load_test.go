@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const exprGrammar = `// expressions
+%token identifier int UMINUS
+%left "+" "-"
+%left "*" /* tighter */ "/"
+%right UMINUS
+
+%%
+S : E ;
+E
+	: E "+" E {AddExpr}
+	| E "-" E {SubExpr}
+	| E "*" E {MulExpr}
+	| "-" E %prec UMINUS
+	| identifier {Id2Operand}
+	| "(" {Open} E ")"
+	;
+`
+
+func TestLoadGrammar(t *testing.T) {
+	g, err := LoadGrammar(strings.NewReader(exprGrammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Rule{
+		{symbol: "S", pattern: []string{"E"}},
+		{symbol: "E", pattern: []string{"E", "+", "E"}, action: "AddExpr"},
+		{symbol: "E", pattern: []string{"E", "-", "E"}, action: "SubExpr"},
+		{symbol: "E", pattern: []string{"E", "*", "E"}, action: "MulExpr"},
+		{symbol: "E", pattern: []string{"-", "E"}, prec: "UMINUS"},
+		{symbol: "E", pattern: []string{"identifier"}, action: "Id2Operand"},
+		{symbol: "E", pattern: []string{"(", "@Open", "E", ")"}},
+		{symbol: "@Open", pattern: []string{""}, action: "Open", depth: 1},
+	}
+	if !reflect.DeepEqual(g.rules, want) {
+		for _, r := range g.rules {
+			t.Logf("%s %%prec %q {%s} %d", r.Show("->", -1), r.prec, r.action, r.depth)
+		}
+		t.Fatal("rules differ")
+	}
+	wantPrec := []PrecLevel{
+		{Left, []string{"+", "-"}},
+		{Left, []string{"*", "/"}},
+		{Right, []string{"UMINUS"}},
+	}
+	if !reflect.DeepEqual(g.precedence, wantPrec) {
+		t.Errorf("precedence: got %v, want %v", g.precedence, wantPrec)
+	}
+
+	_, conflicts, err := Generate(g, Options{LALR: true, Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) == 0 {
+		t.Error("no conflicts resolved by precedence")
+	}
+}
+
+func TestLoadGrammarNonterminalCase(t *testing.T) {
+	// the terminals are told from the nonterminals by their rules,
+	// not by the case of their names
+	g, err := LoadGrammar(strings.NewReader(`%token ID
+%%
+list : list item | ;
+item : ID ;
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms, nonterms := g.GetTerminalsAndNoTerminals()
+	if strings.Join(terms, " ") != " ID" || strings.Join(nonterms, " ") != "item list" {
+		t.Errorf("got terminals %q, nonterminals %q", terms, nonterms)
+	}
+	if _, _, err := Generate(g, Options{LALR: true, Strict: true}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadGrammarErrors(t *testing.T) {
+	for _, test := range []struct {
+		src, err string
+	}{
+		{"S : a ;", `1:1: expected %%, found S`},
+		{"%start S\n%%", `1:1: unknown directive`},
+		{"%left\n%%\nS : ;", `1:1: no terminals in precedence declaration`},
+		{"%left \"+\"\n%right \"+\"\n%%\nS : ;", `2:8: precedence of + declared twice`},
+		{"%%\n", `2:1: no rules`},
+		{"%%\nS : \"x\"\n", `3:1: expected "|" or ";", found end of file`},
+		{"%%\nS \"x\" ;", `2:3: expected ":", found "x"`},
+		{"%%\nS : T ;\n", `2:5: undefined: T`},
+		{"%right UMINUS\n%%\nS : ;\n", `1:8: undefined: UMINUS`},
+		{"%%\nS : x ;\n", `2:5: undefined: x`},
+		{"%%\nS : { 1 } ;", `2:7: expected action name, found "1"`},
+		{"%%\nS : \"x\" %prec \"x\" ;", `2:15: no precedence declared for x`},
+		{"%token T\n%%\nS : T ;\nT : ;", `3:5: T is declared as a token and has rules`},
+		{"%%\nS : \"a\" {A} \"b\" | {A} \"c\" ;", `2:20: action A follows 0 symbols here and 1 elsewhere`},
+		{"%%\nS : \"x ;", `2:9: literal not terminated`},
+	} {
+		_, err := LoadGrammar(strings.NewReader(test.src))
+		if err == nil {
+			t.Errorf("%q: no error, want %s", test.src, test.err)
+		} else if err.Error() != test.err {
+			t.Errorf("%q: got %s, want %s", test.src, err, test.err)
+		}
+	}
+}
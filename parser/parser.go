@@ -2,26 +2,111 @@ package parser
 
 import (
 	"fmt"
+	"io"
+	"myGo/ast"
+	"myGo/constant"
+	"myGo/ir"
 	"myGo/mytoken"
+	"myGo/scanner"
+	"myGo/types"
+	"os"
+	"sort"
+	"strings"
 )
 
 // parser manages the parsing process
 type Parser struct {
-	actions ActionTable
-	stack   []int
-	data    []interface{}
+	table  *Table
+	stack  []frame
+	args   []interface{} // the values passed to build and the actions
+	result interface{}   // value of the start symbol, once accepted
+	out    io.Writer     // destination of the generated code; os.Stdout if nil
+	ctx    *context      // semantic state of the current compilation
+	mode   Mode
+
+	file   *mytoken.File     // file being parsed; or nil
+	errors scanner.ErrorList // syntax and semantic errors
+
+	comments commentState // with ParseComments
+
+	// error recovery
+	errState int      // tokens to shift before syntax errors are reported again
+	errDepth int      // index on the stack of the state the error terminal was last shifted from
+	bad      *badNode // input skipped by the current recovery; or nil
+	broken   bool     // a syntax error occurred, semantic actions are off
 }
 
+// NewParser returns a parser running on the parsing table ac. It panics
+// if NewTable cannot convert ac.
 func NewParser(ac ActionTable) *Parser {
-	return &Parser{
-		actions: ac,
-		stack:   []int{0},
-		data:    []interface{}{},
+	t, err := NewTable(ac)
+	if err != nil {
+		panic(err)
 	}
+	return NewTableParser(t)
+}
+
+// NewTableParser returns a parser running on t. Parsers can share a
+// table.
+func NewTableParser(t *Table) *Parser {
+	p := &Parser{
+		table: t,
+		stack: []frame{{}},
+	}
+	p.ctx = newContext(p.error)
+	return p
+}
+
+// SetOutput sets the destination of the code generated by the semantic
+// actions, which each parse writes in textual form once it is done. It
+// must be called before parsing starts.
+func (p *Parser) SetOutput(w io.Writer) {
+	p.out = w
+}
+
+// Code returns the three-address code generated by the semantic actions
+// of the last parse. It stops at the first syntax error.
+func (p *Parser) Code() *ir.Func {
+	return p.ctx.fn
+}
+
+// A frame is an entry of the parser stack: a state and the semantic
+// value of the symbol on which the parser went to it. The value is the
+// newToken shifted for a terminal, the *badNode for the error terminal,
+// and for a nonterminal the result of build and the rule's action, or
+// of a mid-rule action. The frame at the bottom has state 0 and no
+// value.
+type frame struct {
+	state int
+	value interface{}
+}
+
+// state returns the current state.
+func (p *Parser) state() int {
+	return p.stack[len(p.stack)-1].state
+}
+
+// push goes to state on a symbol with the given value.
+func (p *Parser) push(state int, value interface{}) {
+	p.stack = append(p.stack, frame{state, value})
+}
+
+// values returns the values of the n topmost frames, the bottom frame
+// excluded. It returns false if there are fewer frames.
+func (p *Parser) values(n int) ([]interface{}, bool) {
+	if n >= len(p.stack) {
+		return nil, false
+	}
+	p.args = p.args[:0]
+	for _, f := range p.stack[len(p.stack)-n:] {
+		p.args = append(p.args, f.value)
+	}
+	return p.args, true
 }
 
 type newToken struct {
-	tok *mytoken.Token
+	pos mytoken.Pos
+	tok mytoken.Token
 	lit string
 }
 
@@ -30,94 +115,340 @@ func (nt *newToken) String() string {
 }
 
 type Node struct {
-	val  int    // node 的值
-	id   string // 名称，用于符号表和中间代码生成
-	code string // 用于代码生成
-}
-
-// 语义分析栈,用来存放节点(只有非终结符才能生成节点)
-var semStack = make([]Node, 1024)
-var top = 0
-
-// TODO: fill
-// Note: 有些规约里面虽然有语义动作，但不会对语义分析栈造成影响，所以无需为其构建单独的处理函数
-var FunctionTables = map[string]func(){
-	"CheckDup":     CheckDup,
-	"Lexval":       Lexval,
-	"Id2Operand":   Id2Operand,
-	"InstallId":    InstallId,
-	"InstallArray": InstallArray,
-	"AddExpr":      AddExpr,
-	"SubExpr":      SubExpr,
-	"MulExpr":      MulExpr,
-	"DivExpr":      DivExpr,
-	"LogicAnd":     LogicAnd,
-	"LogicOr":      LogicOr,
-	"Equal":        Equal,
-	"NotEqual":     NotEqual,
-	"Large":        Large,
-	"Less":         Less,
-	"ZPrimary":     Zprimary,
-	"FPrimary":     Fprimary,
-	"NPrimary":     Nprimary,
-	"For1":         For1,
-	"NewST":        NewST,
-	"EndBlock":     EndBlock,
-	"Assign":       Assign,
-	"IF1":          IF1,
-}
-
-//前一个有值的词法单元
-var preToke newToken
-var preId newToken
-var preInt newToken
+	pos  mytoken.Pos
+	val  constant.Value // node 的值, Unknown unless it is a constant
+	opnd ir.Operand     // the operand of the three-address code holding the value
+}
+
+// FunctionTables maps the names of the semantic actions in the grammar
+// to their functions.
+var FunctionTables = map[string]func(c *context, x interface{}, args []interface{}) interface{}{
+	"CheckDup":     (*context).CheckDup,
+	"Lexval":       (*context).Lexval,
+	"Id2Operand":   (*context).Id2Operand,
+	"InstallId":    (*context).InstallId,
+	"InstallArray": (*context).InstallArray,
+	"AddExpr":      (*context).AddExpr,
+	"SubExpr":      (*context).SubExpr,
+	"MulExpr":      (*context).MulExpr,
+	"DivExpr":      (*context).DivExpr,
+	"LogicAnd":     (*context).LogicAnd,
+	"LogicOr":      (*context).LogicOr,
+	"Equal":        (*context).Equal,
+	"NotEqual":     (*context).NotEqual,
+	"Large":        (*context).Large,
+	"Less":         (*context).Less,
+	"ZPrimary":     (*context).Zprimary,
+	"FPrimary":     (*context).Fprimary,
+	"NPrimary":     (*context).Nprimary,
+	"For1":         (*context).For1,
+	"NewST":        (*context).NewST,
+	"EndBlock":     (*context).EndBlock,
+	"Assign":       (*context).Assign,
+	"IF1":          (*context).IF1,
+	"IndexExpr":    (*context).IndexExpr,
+}
+
+// Errors returns the errors reported so far.
+func (p *Parser) Errors() scanner.ErrorList {
+	return p.errors
+}
+
+// error records an error at pos.
+func (p *Parser) error(pos mytoken.Pos, msg string) {
+	var position mytoken.Position
+	if p.file != nil {
+		position = p.file.Position(pos)
+	}
+	p.errors.Add(position, msg)
+}
+
+// fail records an error at pos and returns it.
+func (p *Parser) fail(pos mytoken.Pos, msg string) error {
+	p.error(pos, msg)
+	return p.errors[len(p.errors)-1]
+}
+
+// errorExpected reports a syntax error at tok, listing the terminals
+// the current state would have accepted.
+func (p *Parser) errorExpected(tok *newToken) error {
+	var expected []string
+	state := p.state()
+	for tok := mytoken.Token(0); tok < mytoken.NumTokens; tok++ {
+		if p.table.lookup(state, int(tok)) != 0 {
+			expected = append(expected, fmt.Sprintf("%q", tok.String()))
+		}
+	}
+	sort.Strings(expected)
+
+	found := fmt.Sprintf("%q", tok.String())
+	switch {
+	case tok.tok.IsLiteral():
+		found = tok.String() + " " + tok.lit
+	case tok.tok == mytoken.SEMICOLON && tok.lit == "\n":
+		// inserted by the scanner
+		found = "newline"
+	}
+	msg := "unexpected " + found
+	if len(expected) > 0 {
+		msg = "expected " + strings.Join(expected, ", ") + ", found " + found
+	}
+	return p.fail(tok.pos, msg)
+}
+
+// errorSym is the pseudo-terminal used in grammar rules to mark the
+// places where the parser resumes after a syntax error, as in yacc.
+const errorSym = "error"
+
+// badNode is the value of the error pseudo-terminal: the range of the
+// input skipped during recovery.
+type badNode struct {
+	from, to mytoken.Pos
+}
+
+// recover handles a syntax error at tok in panic mode. The stack is
+// unwound to a state that can shift the error terminal, the error is
+// shifted and tokens are discarded until one can follow it. It reports
+// whether tok was discarded.
+func (p *Parser) recover(tok *newToken) (bool, error) {
+	if p.errState == 3 {
+		// error just shifted, tok cannot follow it
+		if tok.tok != mytoken.EOF {
+			return true, nil
+		}
+		// EOF cannot be discarded, look for an error state below the
+		// one the error was shifted from: the reductions since may have
+		// led back to it, and recovering there again would never end
+		limit := len(p.stack) - 1
+		if p.errDepth < limit {
+			limit = p.errDepth
+		}
+		if i := p.errorState(tok, limit, true); i >= 0 {
+			p.shiftError(i, tok)
+			return false, nil
+		}
+		return false, p.fail(tok.pos, "cannot recover from syntax error")
+	}
+
+	var err error
+	if p.errState == 0 {
+		err = p.errorExpected(tok)
+	}
+	p.broken = true
+	i := p.errorState(tok, len(p.stack), true)
+	if i < 0 {
+		i = p.errorState(tok, len(p.stack), false)
+	}
+	if i < 0 {
+		if err == nil {
+			err = p.fail(tok.pos, "cannot recover from syntax error")
+		}
+		return false, err
+	}
+	p.shiftError(i, tok)
+	return false, nil
+}
+
+// errorState returns the index of the topmost state below limit on the
+// stack that can shift the error terminal. If accept is set, the state
+// reached by the shift must also have an action for tok. It returns -1
+// if there is no such state.
+func (p *Parser) errorState(tok *newToken, limit int, accept bool) int {
+	for i := limit - 1; i >= 0; i-- {
+		shift := p.table.lookup(p.stack[i].state, errorTerm)
+		if shift <= 0 {
+			continue
+		}
+		if !accept || p.table.lookup(int(shift-1), term(tok.tok)) != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// shiftError unwinds the stack to the state at index i and shifts the
+// error terminal covering the discarded input.
+func (p *Parser) shiftError(i int, tok *newToken) {
+	from := tok.pos
+	for _, f := range p.stack[i+1:] {
+		if pos := posOf(f.value); pos.IsValid() {
+			from = pos
+			break
+		}
+	}
+	p.stack = p.stack[:i+1]
+	p.bad = &badNode{from, tok.pos}
+	p.errDepth = i
+	p.push(int(p.table.lookup(p.stack[i].state, errorTerm)-1), p.bad)
+	p.errState = 3
+}
+
+// posOf returns the position of a value of the parser stack.
+func posOf(v interface{}) mytoken.Pos {
+	switch v := v.(type) {
+	case newToken:
+		return v.pos
+	case *badNode:
+		return v.from
+	case ast.Node:
+		return v.Pos()
+	case []ast.Stmt:
+		if len(v) > 0 {
+			return v[0].Pos()
+		}
+	}
+	return mytoken.NoPos
+}
 
 func (p *Parser) Parser(tok *newToken, start string, trace bool) (bool, error) {
 	for {
-		action, ok := p.actions[p.stack[len(p.stack)-1]][tok.String()]
-		if !ok {
-			return false, fmt.Errorf("unexpected token: %v", tok.String())
-		}
-		switch action.(type) {
-		case Shift:
-			preToke = *tok
-			if tok.String() == "identifier" {
-				preId = *tok
+		action := p.table.lookup(p.state(), term(tok.tok))
+		if action == 0 {
+			skip, err := p.recover(tok)
+			if skip || err != nil {
+				return false, err
 			}
-			if tok.String() == "int" {
-				preInt = *tok
+			continue
+		}
+		if p.bad != nil {
+			// recovered, the skipped input ends here
+			p.bad.to = tok.pos
+			p.bad = nil
+		}
+		if action > 0 {
+			// shift
+			if p.errState > 0 {
+				p.errState--
 			}
-			nextState := action.(Shift).state
-			p.data = append(p.data, tok.String())
-			p.stack = append(p.stack, nextState)
+			p.push(int(action-1), *tok)
 			return false, nil
-		case Reduce:
-			rule := action.(Reduce).rule
-			if !trace {
-				fmt.Printf("input %v => reduce %s -> %s\n", tok.lit, rule.pattern, rule.symbol)
-			}
-			// 如果发生空产生式我们就进行动作执行
-			if rule.pattern[0] != "" {
-				popCount := len(rule.pattern)
-				p.stack = p.stack[0 : len(p.stack)-popCount]
-			} else {
-				FunctionTables[rule.symbol]()
-			}
+		}
 
-			if rule.symbol == start {
-				// Accept
-				return true, nil
-			}
+		// reduce
+		r := int(^action)
+		rule := p.table.rules[r]
+		if !trace {
+			fmt.Printf("input %v => reduce %s -> %s\n", tok.lit, rule.pattern, rule.symbol)
+		}
+		// the symbols of the rule are popped; a mid-rule action also
+		// sees the values of the symbols before it, which stay
+		popCount := 0
+		if rule.pattern[0] != "" {
+			popCount = len(rule.pattern)
+		}
+		args, ok := p.values(popCount + rule.depth)
+		if !ok {
+			return false, p.fail(tok.pos, "internal error: parser stack underflow")
+		}
+		var value interface{}
+		if popCount > 0 {
+			value = build(rule, args)
+		}
+		if f, ok := FunctionTables[rule.action]; ok && !p.broken {
+			value = f(p.ctx, value, args)
+		}
+		p.stack = p.stack[:len(p.stack)-popCount]
 
-			state := p.stack[len(p.stack)-1]
-			action, ok = p.actions[state][rule.symbol]
-			if _, well := action.(Reduce); !ok || well {
-				panic(fmt.Errorf("parse error, bad next state"))
-			}
+		if rule.symbol == start {
+			// Accept
+			p.result = value
+			return true, nil
+		}
 
-			p.stack = append(p.stack, action.(Shift).state)
-		default:
-			return false, fmt.Errorf("unkonw action!")
+		state := p.state()
+		next := p.table.next(state, r)
+		if next < 0 {
+			return false, p.fail(tok.pos, fmt.Sprintf("internal error: no goto on %s in state %d", rule.symbol, state))
+		}
+		p.push(next, value)
+	}
+}
+
+// Parse scans src, which must be the content of file, and feeds the
+// tokens to the parser until start is accepted. It returns the syntax
+// tree built along the way and, if there were errors, a
+// scanner.ErrorList of them.
+func (p *Parser) Parse(file *mytoken.File, src []byte, start string) (*ast.File, error) {
+	var s scanner.Scanner
+	p.reset(file)
+	var mode scanner.Mode
+	if p.mode&ParseComments != 0 {
+		mode = scanner.ScanComments
+	}
+	s.Init(file, src, p.errors.Add, mode)
+	return p.run(&s, start)
+}
+
+// ParseTokens is like Parse, but it takes the tokens of file from src
+// instead of scanning them itself.
+func (p *Parser) ParseTokens(file *mytoken.File, src TokenSource, start string) (*ast.File, error) {
+	p.reset(file)
+	return p.run(src, start)
+}
+
+// reset prepares p for parsing file.
+func (p *Parser) reset(file *mytoken.File) {
+	p.file = file
+	p.errors = nil
+	p.stack = append(p.stack[:0], frame{})
+	p.result = nil
+	p.ctx = newContext(p.error)
+	p.errState, p.errDepth, p.bad, p.broken = 0, 0, nil, false
+	p.comments = commentState{}
+}
+
+// run feeds the tokens of src to the parser until start is accepted.
+// Comments are collected with ParseComments, or else skipped.
+func (p *Parser) run(src TokenSource, start string) (*ast.File, error) {
+	defer p.writeCode()
+	for {
+		pos, tok, lit := src.Scan()
+		if p.mode&ParseComments != 0 {
+			p.comment(pos, tok, lit)
+		}
+		if tok == mytoken.COMMENT {
+			continue
+		}
+		ok, err := p.Parser(&newToken{pos, tok, lit}, start, p.mode&Trace == 0)
+		if err != nil {
+			return nil, p.err()
+		}
+		if ok {
+			break
+		}
+		if tok == mytoken.EOF {
+			// the source does not end, or the parser cannot finish on EOF
+			p.error(pos, "unexpected end of input")
+			return nil, p.err()
+		}
+	}
+	f, _ := p.result.(*ast.File)
+	if f != nil {
+		f.Unresolved = p.ctx.unresolved
+		if p.mode&CheckTypes != 0 {
+			if err := types.Check(p.file, f, nil); err != nil {
+				p.errors = append(p.errors, err.(scanner.ErrorList)...)
+			}
 		}
 	}
+	if f != nil && p.mode&ParseComments != 0 {
+		p.attachComments(f)
+	}
+	return f, p.err()
+}
+
+// writeCode writes the generated code to the output of p.
+func (p *Parser) writeCode() {
+	out := p.out
+	if out == nil {
+		out = os.Stdout
+	}
+	ir.Fprint(out, p.ctx.fn)
+}
+
+// err returns the errors of the parse as a scanner.ErrorList sorted by
+// position, keeping the first error of each line; or nil.
+func (p *Parser) err() error {
+	p.errors.RemoveMultiples()
+	return p.errors.Err()
 }
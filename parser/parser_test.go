@@ -1,9 +1,17 @@
 package parser
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"myGo/ast"
+	"myGo/ir"
 	"myGo/mytoken"
 	"myGo/scanner"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TODO: think about lalr
@@ -14,18 +22,12 @@ func TestNewParser(t *testing.T) {
 		i := 2
 	}
 	`)
-	var s scanner.Scanner
-	file := mytoken.Newfile("", 0, len(src))
+	file := mytoken.NewFileSet().AddFile("", -1, len(src))
 	G.CollectSymbols()
 	ac := ComputeActions(G)
 	p := NewParser(ac)
-	s.Init(file, src, nil, scanner.ScanComments)
-	for {
-		_, tok, lit := s.Scan()
-		ok, _ := p.Parser(&newToken{&tok, lit}, "Program", true)
-		if ok {
-			break
-		}
+	if _, err := p.Parse(file, src, "Program"); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -36,41 +38,690 @@ func TestFor(t *testing.T) {
 		i = i + 1
 	}
 	`)
-	var s scanner.Scanner
-	file := mytoken.Newfile("", 0, len(src))
+	file := mytoken.NewFileSet().AddFile("", -1, len(src))
 	G.CollectSymbols()
 	ac := ComputeActions(G)
 	p := NewParser(ac)
-	s.Init(file, src, nil, scanner.ScanComments)
-	for {
-		_, tok, lit := s.Scan()
-		ok, _ := p.Parser(&newToken{&tok, lit}, "Program", true)
-		if ok {
-			break
-		}
+	if _, err := p.Parse(file, src, "Program"); err != nil {
+		t.Fatal(err)
 	}
 }
 
 func TestNewParser2(t *testing.T) {
 	src := []byte(`id+id*id`)
-	var g = &Grammar{[]*Rule{
-		{"E'", []string{"E"}},
-		{"E", []string{"E", "*", "E"}},
-		{"E", []string{"E", "+", "E"}},
-		{"E", []string{"identifier"}},
-	}, nil}
-	var s scanner.Scanner
-	file := mytoken.Newfile("", 0, len(src))
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "E'", pattern: []string{"E", ";"}},
+		{symbol: "E", pattern: []string{"E", "*", "E"}},
+		{symbol: "E", pattern: []string{"E", "+", "E"}},
+		{symbol: "E", pattern: []string{"identifier"}},
+	}}
+	file := mytoken.NewFileSet().AddFile("", -1, len(src))
 	g.CollectSymbols()
 	ac := ComputeActions(g)
 	ac.Dump()
 	p := NewParser(ac)
-	s.Init(file, src, nil, scanner.ScanComments)
-	for {
-		_, tok, lit := s.Scan()
-		ok, _ := p.Parser(&newToken{&tok, lit}, "E'", true)
-		if ok {
-			break
+	if _, err := p.Parse(file, src, "E'"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	src := []byte(`i := -1
+for i < 1 {
+	j := (i)
+	i = a[i] + 1
+}
+`)
+	file := mytoken.Newfile("test.go", 1, len(src))
+	p := NewTableParser(MyGo)
+	f, err := p.Parse(file, src, "Program")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Stmts) != 2 {
+		t.Fatalf("got %d statements, want 2", len(f.Stmts))
+	}
+
+	decl, ok := f.Stmts[0].(*ast.AssignStmt)
+	if !ok || decl.Tok != mytoken.DEFINE {
+		t.Fatalf("statement 0: got %T, want := *ast.AssignStmt", f.Stmts[0])
+	}
+	if x, ok := decl.Rhs[0].(*ast.UnaryExpr); !ok || x.Op != mytoken.SUB {
+		t.Errorf("got %T, want *ast.UnaryExpr", decl.Rhs[0])
+	}
+
+	loop, ok := f.Stmts[1].(*ast.ForStmt)
+	if !ok {
+		t.Fatalf("statement 1: got %T, want *ast.ForStmt", f.Stmts[1])
+	}
+	if _, ok := loop.Cond.(*ast.BinaryExpr); !ok {
+		t.Errorf("for condition: got %T, want *ast.BinaryExpr", loop.Cond)
+	}
+	if n := len(loop.Body.List); n != 2 {
+		t.Fatalf("for body: got %d statements, want 2", n)
+	}
+	if s, ok := loop.Body.List[0].(*ast.AssignStmt); !ok {
+		t.Errorf("got %T, want *ast.AssignStmt", loop.Body.List[0])
+	} else if _, ok := s.Rhs[0].(*ast.ParenExpr); !ok {
+		t.Errorf("got %T, want *ast.ParenExpr", s.Rhs[0])
+	}
+	assign := loop.Body.List[1].(*ast.AssignStmt)
+	sum := assign.Rhs[0].(*ast.BinaryExpr)
+	if _, ok := sum.X.(*ast.IndexExpr); !ok {
+		t.Errorf("got %T, want *ast.IndexExpr", sum.X)
+	}
+
+	// check a few positions
+	for _, test := range []struct {
+		node      ast.Node
+		line, col int
+	}{
+		{decl, 1, 1},
+		{decl.Rhs[0], 1, 6},
+		{loop, 2, 1},
+		{loop.Body, 2, 11},
+		{assign, 4, 2},
+		{sum.X, 4, 6},
+		{sum.Y, 4, 13},
+	} {
+		pos := file.Position(test.node.Pos())
+		if pos.Line != test.line || pos.Column != test.col {
+			t.Errorf("%T: got position %s, want %d:%d", test.node, pos, test.line, test.col)
+		}
+	}
+	if end := file.Position(loop.End()); end.Line != 5 || end.Column != 2 {
+		t.Errorf("for statement ends at %s, want 5:2", end)
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	src := []byte(`/* count */ i := 0
+for i < 3 /* times */ {
+	i = i + 1
+}
+/* done */`)
+	file := mytoken.Newfile("x.go", 1, len(src))
+	f, err := ParseFile(file, src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Stmts) != 2 {
+		t.Fatalf("got %d statements, want 2", len(f.Stmts))
+	}
+	if pos := file.Position(f.Stmts[0].Pos()); pos.Line != 1 || pos.Column != 13 {
+		t.Errorf("declaration at %s, want x.go:1:13", pos)
+	}
+
+	src = []byte("i := (1\n")
+	_, err = ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, 0)
+	if list, ok := err.(scanner.ErrorList); !ok || list[0].Pos.String() != "x.go:1:8" {
+		t.Errorf("got error %v, want one at x.go:1:8", err)
+	}
+
+	// errors in generated code point back at the template
+	src = []byte("//line tmpl.go:40:3\ni := (1\n")
+	_, err = ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, 0)
+	if list, ok := err.(scanner.ErrorList); !ok || list[0].Pos.String() != "tmpl.go:40:10" {
+		t.Errorf("got error %v, want one at tmpl.go:40:10", err)
+	}
+
+	// columns count bytes, also after non-ASCII identifiers
+	src = []byte("größe := 1\nπ := größe + (1\n")
+	_, err = ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, 0)
+	if list, ok := err.(scanner.ErrorList); !ok || list[0].Pos.String() != "x.go:2:19" {
+		t.Errorf("got error %v, want one at x.go:2:19", err)
+	}
+}
+
+func TestParseSemicolons(t *testing.T) {
+	src := []byte(`i := 0; j := 1
+
+
+for i < 3 { i = i + 1; j = j * 2 }
+if j > 4 {
+
+	j = 0;
+} else { j = 1 }
+{}`)
+	f, err := ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Stmts) != 5 {
+		t.Fatalf("got %d statements, want 5", len(f.Stmts))
+	}
+	if n := len(f.Stmts[2].(*ast.ForStmt).Body.List); n != 2 {
+		t.Errorf("got %d statements in the loop, want 2", n)
+	}
+	if n := len(f.Stmts[3].(*ast.IfStmt).Body.List); n != 1 {
+		t.Errorf("got %d statements in the if, want 1", n)
+	}
+
+	// an operator at the end of a line continues the statement
+	src = []byte("i := 1 +\n2\n")
+	if _, err := ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, 0); err != nil {
+		t.Error(err)
+	}
+	src = []byte("i := 1\n+ 2 i\n")
+	if _, err := ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, 0); err == nil {
+		t.Error("no error for two statements on a line")
+	}
+}
+
+func TestParseResolve(t *testing.T) {
+	src := []byte(`i := 1
+{
+	i := i + x
+	j := i
+}
+k := j + i
+if i > 0 {
+	y := 2
+} else {
+	y := 3
+}
+`)
+	f, err := ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, id := range f.Unresolved {
+		got = append(got, fmt.Sprintf("%s@%d", id.Name, id.Pos()))
+	}
+	// x and the j outside its block; the i on the right of the inner
+	// declaration is the outer one
+	if want := "[x@20 j@37]"; fmt.Sprint(got) != want {
+		t.Errorf("got unresolved %v, want %s", got, want)
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	src := []byte(`// Package doc.
+
+// i counts.
+// It starts at 0.
+i := 0 // trailing
+
+/* the loop */ for i < 3 {
+	// increment
+	i = i + 1
+	/* not a doc */
+
+	j := i
+}
+// the end
+`)
+	file := mytoken.Newfile("", 1, len(src))
+	f, err := ParseFile(file, src, ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var groups []string
+	for _, g := range f.Comments {
+		groups = append(groups, g.Text())
+	}
+	want := []string{"Package doc.\n", "i counts.\nIt starts at 0.\n", "trailing\n", " the loop\n", "increment\n", " not a doc\n", "the end\n"}
+	if fmt.Sprintf("%q", groups) != fmt.Sprintf("%q", want) {
+		t.Errorf("got comment groups %q, want %q", groups, want)
+	}
+
+	loop := f.Stmts[1].(*ast.ForStmt)
+	for _, test := range []struct {
+		stmt ast.Stmt
+		doc  string
+	}{
+		{f.Stmts[0], "i counts.\nIt starts at 0.\n"},
+		{loop, " the loop\n"},
+		{loop.Body.List[0], "increment\n"},
+		{loop.Body.List[1], ""},
+	} {
+		if got := f.Docs[test.stmt].Text(); got != test.doc {
+			t.Errorf("%T: got doc %q, want %q", test.stmt, got, test.doc)
+		}
+	}
+	if len(f.Docs) != 3 {
+		t.Errorf("got %d docs, want 3", len(f.Docs))
+	}
+
+	// without ParseComments, the comments are skipped
+	f, err = ParseFile(mytoken.Newfile("", 1, len(src)), src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Comments != nil || f.Docs != nil {
+		t.Errorf("got comments %v, docs %v", f.Comments, f.Docs)
+	}
+}
+
+func TestParseFileSet(t *testing.T) {
+	// the files of a package share the positions of a FileSet
+	fset := mytoken.NewFileSet()
+	srcs := []string{"i := 1\nj := i + 1\n", "k := 2\nk := 3\n"}
+	var files []*ast.File
+	var errs []error
+	for i, src := range srcs {
+		file := fset.AddFile(fmt.Sprintf("f%d.go", i), -1, len(src))
+		f, err := ParseFile(file, []byte(src), 0)
+		files = append(files, f)
+		errs = append(errs, err)
+	}
+	if errs[0] != nil {
+		t.Fatal(errs[0])
+	}
+	if errs[1] == nil || errs[1].Error() != "f1.go:2:1: k redeclared in this block" {
+		t.Errorf("got error %v", errs[1])
+	}
+
+	sum := files[0].Stmts[1].(*ast.AssignStmt).Rhs[0]
+	if pos := fset.Position(sum.Pos()); pos.String() != "f0.go:2:6" {
+		t.Errorf("got position %s, want f0.go:2:6", pos)
+	}
+	k := files[1].Stmts[1].(*ast.AssignStmt).Lhs[0]
+	if f := fset.File(k.Pos()); f == nil || f.Name() != "f1.go" {
+		t.Errorf("got file %v, want f1.go", f)
+	}
+	if pos := fset.Position(k.Pos()); pos.String() != "f1.go:2:1" {
+		t.Errorf("got position %s, want f1.go:2:1", pos)
+	}
+}
+
+func TestParseExpr(t *testing.T) {
+	x, err := ParseExpr("1 + a[i] * (2 - b)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, ok := x.(*ast.BinaryExpr)
+	if !ok || sum.Op != mytoken.ADD {
+		t.Fatalf("got %T, want *ast.BinaryExpr", x)
+	}
+	if y, ok := sum.Y.(*ast.BinaryExpr); !ok {
+		t.Errorf("got %T, want *ast.BinaryExpr", sum.Y)
+	} else if _, ok := y.Y.(*ast.ParenExpr); !ok {
+		t.Errorf("got %T, want *ast.ParenExpr", y.Y)
+	}
+	if sum.Pos() != 1 || sum.End() != 19 {
+		t.Errorf("got range [%d, %d), want [1, 19)", sum.Pos(), sum.End())
+	}
+
+	for _, test := range []struct {
+		src, err string
+	}{
+		{"a := 1", "1:1: expected expression"},
+		{"a; b", "1:4: expected expression"},
+		{"a +", `1:4: expected "!", "(", "+", "-", "identifier", "int", found "EOF"`},
+		{"", "1:1: expected expression"},
+		{" ", "1:2: expected expression"},
+		{";", "1:2: expected expression"},
+	} {
+		if _, err := ParseExpr(test.src); err == nil || err.Error() != test.err {
+			t.Errorf("%q: got error %v, want %s", test.src, err, test.err)
+		}
+	}
+}
+
+// tokens is a TokenSource returning the tokens of a slice.
+type tokens []newToken
+
+func (s *tokens) Scan() (mytoken.Pos, mytoken.Token, string) {
+	tok := (*s)[0]
+	if len(*s) > 1 {
+		*s = (*s)[1:]
+	}
+	return tok.pos, tok.tok, tok.lit
+}
+
+func TestParseTokens(t *testing.T) {
+	// x := 1, with a comment and the positions of a file of 20 bytes
+	src := &tokens{
+		{1, mytoken.COMMENT, "/* x */"},
+		{9, mytoken.IDENT, "x"},
+		{11, mytoken.DEFINE, ""},
+		{14, mytoken.INT, "1"},
+		{20, mytoken.EOF, ""},
+	}
+	p := NewTableParser(MyGo)
+	p.SetOutput(io.Discard)
+	f, err := p.ParseTokens(mytoken.Newfile("", 1, 20), src, "Program")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decl := f.Stmts[0].(*ast.AssignStmt)
+	if decl.Pos() != 9 || decl.Rhs[0].(*ast.BasicLit).Value != "1" {
+		t.Errorf("got declaration at %d of %v", decl.Pos(), decl.Rhs[0])
+	}
+
+	// a source that ends too early
+	src = &tokens{{1, mytoken.IDENT, "x"}, {2, mytoken.DEFINE, ""}, {4, mytoken.EOF, ""}}
+	if _, err := p.ParseTokens(mytoken.Newfile("", 1, 4), src, "Program"); err == nil {
+		t.Error("no error for a truncated input")
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	// group writes x with parentheses around each binary expression
+	var group func(x ast.Expr) string
+	group = func(x ast.Expr) string {
+		switch x := x.(type) {
+		case *ast.BinaryExpr:
+			return "(" + group(x.X) + " " + x.Op.String() + " " + group(x.Y) + ")"
+		case *ast.BasicLit:
+			return x.Value
+		case *ast.Ident:
+			return x.Name
+		}
+		return fmt.Sprintf("%T", x)
+	}
+
+	for _, test := range []struct {
+		src, want string
+	}{
+		{"8 - 4 - 2", "((8 - 4) - 2)"},
+		{"8 / 4 / 2", "((8 / 4) / 2)"},
+		{"1 + 2 * 3 - 4", "((1 + (2 * 3)) - 4)"},
+		{"1 + 2 == 3", "((1 + 2) == 3)"},
+		{"1 < 2 && 2 > 1 || 0 != 1", "(((1 < 2) && (2 > 1)) || (0 != 1))"},
+	} {
+		src := []byte("x := " + test.src + "\n")
+		p := NewTableParser(MyGo)
+		p.SetOutput(io.Discard)
+		f, err := p.Parse(mytoken.Newfile("", 1, len(src)), src, "Program")
+		if err != nil {
+			t.Errorf("%s: %v", test.src, err)
+			continue
+		}
+		if got := group(f.Stmts[0].(*ast.AssignStmt).Rhs[0]); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.src, got, test.want)
+		}
+	}
+}
+
+func TestParseConcurrent(t *testing.T) {
+	src := []byte(`i := 1
+	for i < 10 {
+		j := i * 2
+		if j > 4 {
+			i = i + j
+		}
+	}
+	`)
+	parse := func() (string, error) {
+		var out bytes.Buffer
+		p := NewTableParser(MyGo)
+		p.SetOutput(&out)
+		_, err := p.Parse(mytoken.Newfile("", 1, len(src)), src, "Program")
+		return out.String(), err
+	}
+
+	want, err := parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 8
+	var wg sync.WaitGroup
+	results := make([]string, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = parse()
+		}(i)
+	}
+	wg.Wait()
+	for i := range results {
+		if errs[i] != nil {
+			t.Errorf("parse %d: %v", i, errs[i])
+		} else if results[i] != want {
+			t.Errorf("parse %d: got\n%s\nwant\n%s", i, results[i], want)
+		}
+	}
+}
+
+func TestParseDeepNesting(t *testing.T) {
+	const n = 5000
+	src := []byte("x := " + strings.Repeat("(", n) + "1" + strings.Repeat(")", n) + "\n" +
+		strings.Repeat("{\n", n) + "y := x\n" + strings.Repeat("}\n", n))
+	p := NewTableParser(MyGo)
+	p.SetOutput(io.Discard)
+	f, err := p.Parse(mytoken.Newfile("", 1, len(src)), src, "Program")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.stack) != 1 {
+		t.Errorf("%d frames left on the stack after accepting", len(p.stack))
+	}
+
+	x := f.Stmts[0].(*ast.AssignStmt).Rhs[0]
+	depth := 0
+	for paren, ok := x.(*ast.ParenExpr); ok; paren, ok = paren.X.(*ast.ParenExpr) {
+		depth++
+	}
+	if depth != n {
+		t.Errorf("got %d parentheses, want %d", depth, n)
+	}
+	depth = 0
+	for block, ok := f.Stmts[1].(*ast.BlockStmt); ok; block, ok = block.List[0].(*ast.BlockStmt) {
+		depth++
+	}
+	if depth != n {
+		t.Errorf("got %d nested blocks, want %d", depth, n)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		src  string
+		line int
+		col  int
+		msg  string
+	}{
+		{"i := 1\ni := 2\n", 2, 1, "i redeclared in this block"},
+		{"i := 1 / 0\n", 1, 10, "division by zero"},
+		{"i := 1_000 / 0x0\n", 1, 14, "division by zero"},
+		{"i := 99999999999999999999\n", 1, 6, "constant 99999999999999999999 overflows int"},
+		{"i := 0\nj := i / (2 - 2)\n", 2, 10, "division by zero"},
+		{"a := 1\na [2]var\n", 2, 1, "a redeclared in this block"},
+		{"i := 1\na [i]var\n", 2, 4, "invalid array length i"},
+		{"a [3000000000]var\n", 1, 4, "constant 3000000000 overflows int"},
+		{"i := 2147483647 + 1\n", 1, 6, "constant 2147483648 overflows int"},
+		{"i := 0\ni = 3000000000\n", 2, 5, "constant 3000000000 overflows int"},
+		{"i := 0\nj := i + 2 * 4294967296\n", 2, 10, "constant 8589934592 overflows int"},
+		{"i := (1\n", 1, 8, `expected "!=", "&&", ")", "*", "+", "-", "/", "<", "==", ">", "||", found newline`},
+		{"if 1 {\n\ti := 1 }\n}\n", 3, 1, `expected ";", "EOF", found "}"`},
+	} {
+		src := []byte(test.src)
+		p := NewTableParser(MyGo)
+		p.SetOutput(io.Discard)
+		_, err := p.Parse(mytoken.Newfile("x.go", 1, len(src)), src, "Program")
+		list, ok := err.(scanner.ErrorList)
+		if !ok || len(list) != 1 {
+			t.Errorf("%q: got error %v, want one in a scanner.ErrorList", test.src, err)
+			continue
+		}
+		e := list[0]
+		if e.Pos.Filename != "x.go" || e.Pos.Line != test.line || e.Pos.Column != test.col {
+			t.Errorf("%q: got position %s, want x.go:%d:%d", test.src, e.Pos, test.line, test.col)
+		}
+		if !strings.Contains(e.Msg, test.msg) {
+			t.Errorf("%q: got message %q, want %q", test.src, e.Msg, test.msg)
+		}
+	}
+}
+
+// Only constant expressions are folded, with exact values until they
+// are stored.
+func TestParseConstants(t *testing.T) {
+	for _, src := range []string{
+		"i := 0\nj := 1 / i\n",
+		"i := 1 - 1\nj := 2 / i\n",
+		"i := -2147483648\n",
+		"i := 99999999999999999999 - 99999999999999999998\n",
+		"i := 4294967296 / 2 - 2147483649\n",
+	} {
+		b := []byte(src)
+		p := NewTableParser(MyGo)
+		p.SetOutput(io.Discard)
+		if _, err := p.Parse(mytoken.Newfile("x.go", 1, len(b)), b, "Program"); err != nil {
+			t.Errorf("%q: %v", src, err)
+		}
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	src := []byte("i := 1\nj := a[i] + 2\nif j > i {\n\ti = -j\n}\n")
+	var out bytes.Buffer
+	p := NewTableParser(MyGo)
+	p.SetOutput(&out)
+	if _, err := p.Parse(mytoken.Newfile("x.go", 1, len(src)), src, "Program"); err != nil {
+		t.Fatal(err)
+	}
+	const want = `i  =  1
+t0 = a[i]
+t1 = t0 + 2
+j  =  t1
+t2 = j lg i
+if  t2 .false goto  L0
+t3 = -j
+i = t3
+L0
+`
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	code := p.Code()
+	if got := code.String(); got != want {
+		t.Errorf("Code returns\n%s\nwant\n%s", got, want)
+	}
+	if len(code.Instrs) != 9 {
+		t.Fatalf("got %d instructions, want 9", len(code.Instrs))
+	}
+	if load, ok := code.Instrs[1].(*ir.Load); !ok || load.Base != (ir.Var{Name: "a"}) || load.Index != (ir.Var{Name: "i"}) {
+		t.Errorf("got %#v, want a load of a[i]", code.Instrs[1])
+	}
+	if jump, ok := code.Instrs[5].(*ir.CondJump); !ok || jump.Target != code.Instrs[8] {
+		t.Errorf("got %#v, want a jump to the last instruction", code.Instrs[5])
+	}
+}
+
+// An identifier and an index start both array declarations and
+// expressions; the token after the index tells them apart.
+func TestParseArrays(t *testing.T) {
+	src := []byte("a [3]var\na[1] = 2\nb := a[a[0]] + 1\n")
+	var out bytes.Buffer
+	p := NewTableParser(MyGo)
+	p.SetOutput(&out)
+	f, err := p.Parse(mytoken.Newfile("x.go", 1, len(src)), src, "Program")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decl, ok := f.Stmts[0].(*ast.DeclStmt)
+	if !ok {
+		t.Fatalf("got %T, want *ast.DeclStmt", f.Stmts[0])
+	}
+	spec := decl.Decl.(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+	typ, ok := spec.Type.(*ast.ArrayType)
+	if !ok || spec.Names[0].Name != "a" || typ.Len.(*ast.BasicLit).Value != "3" || typ.Elt.(*ast.Ident).Name != "var" {
+		t.Errorf("got declaration of %s %#v", spec.Names[0].Name, spec.Type)
+	}
+	if _, ok := f.Stmts[1].(*ast.AssignStmt); !ok {
+		t.Errorf("got %T, want *ast.AssignStmt", f.Stmts[1])
+	}
+	if len(f.Unresolved) != 0 {
+		t.Errorf("unresolved identifiers %v", f.Unresolved)
+	}
+	const want = `t0 = a[1]
+a[1] = 2
+t1 = a[0]
+t2 = a[t1]
+t3 = t2 + 1
+b  =  t3
+`
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseRecovery(t *testing.T) {
+	src := []byte(`i := := 1
+j := (+) + 2
+if j > 0 {
+	k := ]
+}
+`)
+	p := NewTableParser(MyGo)
+	p.SetOutput(io.Discard)
+	file := mytoken.Newfile("", 1, len(src))
+	f, err := p.Parse(file, src, "Program")
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	var lines []int
+	for _, e := range p.Errors() {
+		lines = append(lines, e.Pos.Line)
+	}
+	if fmt.Sprint(lines) != "[1 2 4]" {
+		t.Errorf("got errors on lines %v, want [1 2 4]:\n%v", lines, p.Errors())
+	}
+	if f == nil {
+		t.Fatal("no syntax tree")
+	}
+
+	bad, ok := f.Stmts[0].(*ast.BadStmt)
+	if !ok {
+		t.Fatalf("got %T, want *ast.BadStmt", f.Stmts[0])
+	}
+	if pos := file.Position(bad.Pos()); pos.Line != 1 || pos.Column != 1 {
+		t.Errorf("bad statement starts at %s, want 1:1", pos)
+	}
+	var j *ast.AssignStmt
+	for _, s := range f.Stmts {
+		if s, ok := s.(*ast.AssignStmt); ok && s.Lhs[0].(*ast.Ident).Name == "j" {
+			j = s
+		}
+	}
+	if j == nil {
+		t.Fatal("declaration of j is missing")
+	}
+	paren := j.Rhs[0].(*ast.BinaryExpr).X.(*ast.ParenExpr)
+	if _, ok := paren.X.(*ast.BadExpr); !ok {
+		t.Errorf("got %T, want *ast.BadExpr", paren.X)
+	}
+	body := f.Stmts[len(f.Stmts)-1].(*ast.IfStmt).Body
+	if _, ok := body.List[0].(*ast.BadStmt); !ok {
+		t.Errorf("got %T, want *ast.BadStmt", body.List[0])
+	}
+}
+
+// An unterminated block must end the parse with an error: recovering on
+// EOF must not shift the error terminal from the same state again.
+func TestParseUnterminated(t *testing.T) {
+	for _, src := range []string{
+		"i := 1\nif i > 0 {\n\ti = 2\n",
+		"i := 0\nfor i < 3 {\n i = i + 1\n",
+		"{ i := 1 ",
+		"{\n\t{\n\t\ti := 1\n\t}\n",
+		// empty statements between semicolons
+		"{ ;",
+		"{ -",
+		"{ (",
+		"{ ; ;",
+		"i := 1; { i = 2;",
+		"if 1 {\n;\n\ti := (\n",
+	} {
+		done := make(chan error, 1)
+		go func(src []byte) {
+			_, err := ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, 0)
+			done <- err
+		}([]byte(src))
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Errorf("%q: no error", src)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%q: the parse does not end", src)
 		}
 	}
 }
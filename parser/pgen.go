@@ -73,7 +73,7 @@ func (is ItemSet) Equal(other ItemSet) bool {
 	return true
 }
 
-func (is ItemSet) Dump(log log.Logger) {
+func (is ItemSet) Dump(log *log.Logger) {
 	for item := range is {
 		log.Println(" ", item.rule.Show("->", item.pos))
 	}
@@ -91,34 +91,27 @@ func (is ItemSet) Closure(grammar *Grammar) {
 				continue
 			}
 			// If we haven't yet added
-			if !IsTerminals(sym) && !added[sym] {
+			if !grammar.isTerminal(sym) && !added[sym] {
 				for _, rule := range grammar.rules {
 					if rule.symbol == sym {
-						if pos := item.pos; pos+1 == len(item.rule.pattern) {
-							is.Add(Item{rule, item.next, 0})
-							//is.Add(Item{rule, "",0})
-						} else {
-							var nextString string
-							mstring := make(map[string]bool)
-							var nstring []string
-							for set := range first[item.rule.pattern[pos+1]] {
-								if set == "" {
-									for _, m := range strings.Split(item.next, "#") {
-										mstring[m] = true
-									}
-								}
+						// the lookaheads are FIRST of what follows sym,
+						// and the item's own if that can be empty
+						mstring := make(map[string]bool)
+						for set := range FirstSeq(first, item.rule.pattern[item.pos+1:]) {
+							if set != "" {
 								mstring[set] = true
+								continue
 							}
-							for ms := range mstring {
-								if mstring[ms] {
-									nstring = append(nstring, ms)
-								}
+							for _, m := range strings.Split(item.next, "#") {
+								mstring[m] = true
 							}
-							sort.Slice(nstring, func(i, j int) bool { return nstring[i] < nstring[j] })
-							nextString = strings.Join(nstring, "#")
-							is.Add(Item{rule, nextString, 0})
-							//is.Add(Item{rule,"",0})
 						}
+						nstring := make([]string, 0, len(mstring))
+						for ms := range mstring {
+							nstring = append(nstring, ms)
+						}
+						sort.Strings(nstring)
+						is.Add(Item{rule, strings.Join(nstring, "#"), 0})
 						changed = true
 						added[sym] = true
 					}
@@ -141,7 +134,13 @@ func (is ItemSet) Goto(grammar *Grammar, x string) ItemSet {
 	return out
 }
 
+// ComputeActions builds the canonical LR(1) parsing table of grammar.
 func ComputeActions(grammar *Grammar) ActionTable {
+	table, _, _ := Generate(grammar, Options{})
+	return table
+}
+
+func computeLR1(grammar *Grammar, r *resolver) ActionTable {
 	first := grammar.First()
 	follow := grammar.Follow(first)
 
@@ -154,6 +153,12 @@ func ComputeActions(grammar *Grammar) ActionTable {
 	}
 	states[0].Closure(grammar)
 
+	symbols := make([]string, 0, len(grammar.symbols))
+	for sym := range grammar.symbols {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
 	// Construcr the parsing list by computing goto() for each state and
 	// terminal
 	// C中的每个项集I
@@ -162,7 +167,7 @@ func ComputeActions(grammar *Grammar) ActionTable {
 		actions := make(map[string]Action)
 		allActions = append(allActions, actions)
 		// 每个文法符号
-		for term := range grammar.symbols {
+		for _, term := range symbols {
 			c := set.Goto(grammar, term)
 			// GOTO是否为空
 			if c.Empty() {
@@ -186,52 +191,42 @@ func ComputeActions(grammar *Grammar) ActionTable {
 	}
 
 	// Add a reduce action for all items that have consumed the full rule.
+	ruleIds := make(map[*Rule]int)
+	for i, rule := range grammar.rules {
+		ruleIds[rule] = i
+	}
 	for i, set := range states {
 		actions := allActions[i]
+		var items []core
+		seen := make(map[core]bool)
 		for item := range set {
+			if c := (core{item.rule, item.pos}); !seen[c] {
+				seen[c] = true
+				items = append(items, c)
+			}
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if a, b := ruleIds[items[i].rule], ruleIds[items[j].rule]; a != b {
+				return a < b
+			}
+			return items[i].pos < items[j].pos
+		})
+		for _, item := range items {
 			// middot 在产生式结尾处了 [A->a.,b]
-			if _, end := item.NextSym(); !end {
+			if _, end := item.next(); !end {
 				continue
 			}
 
 			f := follow[item.rule.symbol]
+			terms := make([]string, 0, len(f))
 			for term := range f {
-				if actions[term] != nil {
-					for _, a := range item.rule.pattern {
-						//fmt.Println(a,precedence(a)," ",precedence(term),term)
-						if precedence(a) > precedence(term) {
-							actions[term] = Reduce{item.rule}
-							break
-						}
-					}
-				} else {
-					actions[term] = Reduce{item.rule}
-				}
+				terms = append(terms, term)
+			}
+			sort.Strings(terms)
+			for _, term := range terms {
+				r.reduce(i, actions, items, item.rule, term)
 			}
 		}
 	}
 	return allActions
 }
-
-func precedence(item string) int {
-	switch item {
-	case "=":
-		return 0
-	case "||":
-		return 1
-	case "&&":
-		return 2
-	case "":
-		return 3
-	case "==", "!=", ">", "<":
-		return 4
-	case "+", "-":
-		return 5
-	case "*", "/":
-		return 6
-	case "[", "]":
-		return 7
-	default:
-		return -1
-	}
-}
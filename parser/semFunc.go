@@ -1,560 +1,387 @@
 package parser
 
 import (
-	"fmt"
-	"os"
-	"strconv"
+	"myGo/ast"
+	"myGo/constant"
+	"myGo/ir"
+	"myGo/mytoken"
+	"myGo/types"
 )
 
-var currentOffset = 0
-
-var numTemp = 0
+// The sizes, in bytes, of the variables of the three-address code.
+const (
+	intSize  = 4
+	boolSize = 1
+)
 
 type Attribute struct {
-	tp     int // 符号类型 0:int 1:bool 2:array
-	num    int // 符号的值
 	len    int // 数组长度
 	offset int // 偏移量
 	values map[int]int
 }
 
-// control[i] 表明符号表i中能够访问的符号表
-// []int 中的数倒序存放
-var control = map[int][]int{
-	0: {0},
-}
+// context holds the semantic state of a single compilation. Every
+// Parser owns its own context, so that several files can be compiled
+// at the same time.
+type context struct {
+	// the operands holding the values of the expressions reduced so far
+	operands map[ast.Expr]Node
 
-var SymbolTables = map[int]map[string]Attribute{
-	0: {},
-}
+	scope      *types.Scope          // the innermost scope
+	temps      map[ir.Temp]Attribute // the temporaries of the three-address code
+	unresolved []*ast.Ident          // the identifiers not declared in any scope
 
-// 当前符号表深度
-var currentTable = 0
-var totalTable = 0
-var labelnum = 0
+	currentOffset int
 
-// 符号表搜索
-func findSymbol(id string) (int, bool) {
-	if v, ok := SymbolTables[currentTable][preToke.lit]; ok {
-		return v.num, true
-	}
-	for i := range control[currentTable] {
-		if sym, ok := SymbolTables[i][id]; ok {
-			return sym.num, true
-		}
-	}
-	return 0, false
+	// 循环的开始和结束标号
+	lbegin []*ir.Label
+	lend   []*ir.Label
+
+	fn   *ir.Func                          // the generated code
+	errh func(pos mytoken.Pos, msg string) // semantic error reporting
 }
 
-func Id2Operand() {
-	var node Node
-	if num, ok := findSymbol(preToke.lit); ok {
-		node = Node{val: num, id: preToke.lit}
-	} else {
-		node = Node{id: preToke.lit}
+func newContext(errh func(pos mytoken.Pos, msg string)) *context {
+	return &context{
+		operands: make(map[ast.Expr]Node),
+		scope:    types.NewScope(nil),
+		temps:    make(map[ir.Temp]Attribute),
+		fn:       ir.NewFunc("main"),
+		errh:     errh,
 	}
-	semStack[top] = node
-	top++
 }
 
-func Lexval() {
-	num, _ := strconv.Atoi(preToke.lit)
-	node := Node{id: "", val: num}
-	semStack[top] = node
-	top++
+// operand returns the operand of the three-address code holding the
+// value of the expression x.
+func (c *context) operand(x interface{}) Node {
+	unknown := constant.MakeUnknown()
+	switch x := x.(type) {
+	case *ast.ParenExpr:
+		return c.operand(x.X)
+	case ast.Expr:
+		if node, ok := c.operands[x]; ok {
+			return node
+		}
+		return Node{pos: x.Pos(), val: unknown, opnd: ir.Const{Value: unknown}}
+	}
+	return Node{val: unknown, opnd: ir.Const{Value: unknown}}
 }
 
-func CheckDup() {
-	if _, ok := SymbolTables[currentTable][preToke.lit]; ok {
-		fmt.Println("重复声明变量")
-	}
-	node := Node{id: preToke.lit}
-	semStack[top] = node
-	top++
+// The semantic actions are called with x, the value the parser built
+// for the left-hand side of their rule, nil for mid-rule actions, and
+// args, the values of the symbols before the action, $1 to $n. They
+// return the value of the left-hand side, or of the mid-rule action.
+
+func (c *context) Id2Operand(x interface{}, args []interface{}) interface{} {
+	c.resolve(x.(*ast.Ident))
+	return x
 }
 
-func InstallId() {
-	attr := Attribute{
-		num:    semStack[top-1].val,
-		offset: currentOffset,
-		len:    1,
-		tp:     1,
-	}
-	SymbolTables[currentTable][semStack[top-2].id] = attr
-	currentOffset = currentOffset + 4
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-2].id, " = ", attr.num)
-	} else {
-		fmt.Println(semStack[top-2].id, " = ", semStack[top-1].id)
+// resolve makes the variable id the operand of id.
+func (c *context) resolve(id *ast.Ident) {
+	// 符号表搜索, from the innermost scope out; the values of variables
+	// are not known at compile time
+	if _, obj := c.scope.LookupParent(id.Name); obj == nil {
+		c.unresolved = append(c.unresolved, id)
 	}
-	// consumer Expr so top--
-	top = top - 2
+	c.operands[id] = Node{pos: id.NamePos, val: constant.MakeUnknown(), opnd: ir.Var{Name: id.Name}}
 }
 
-func InstallArray() {
-	l, _ := strconv.Atoi(preInt.lit)
-	v := make(map[int]int)
-	attr := Attribute{
-		tp:     2,
-		len:    l,
-		offset: currentOffset,
-		values: v,
-	}
-	SymbolTables[currentTable][preId.lit] = attr
-	currentOffset = currentOffset + 4*l
-	top = top - 1
+func (c *context) Lexval(x interface{}, args []interface{}) interface{} {
+	tok := args[0].(newToken)
+	val := constant.MakeFromLiteral(tok.lit, tok.tok)
+	c.operands[x.(ast.Expr)] = Node{pos: tok.pos, val: val, opnd: ir.Const{Value: val}}
+	return x
 }
 
-func AddExpr() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " + ")
-	} else {
-		fmt.Print(semStack[top-2].id, " + ")
-	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
-	}
-	attr := Attribute{
-		tp:     1,
-		len:    1,
-		offset: currentOffset,
-		num:    semStack[top-2].val + semStack[top-1].val,
-	}
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 4
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
-}
-
-func SubExpr() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " - ")
-	} else {
-		fmt.Print(semStack[top-2].id, " - ")
-	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
-	}
-	attr := Attribute{
-		tp:     1,
-		len:    1,
-		offset: currentOffset,
-		num:    semStack[top-2].val - semStack[top-1].val,
-	}
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 4
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
-}
-
-func MulExpr() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " * ")
-	} else {
-		fmt.Print(semStack[top-2].id, " * ")
-	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
-	}
-	attr := Attribute{
-		tp:     1,
-		len:    1,
-		offset: currentOffset,
-		num:    semStack[top-2].val * semStack[top-1].val,
-	}
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 4
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
-}
-
-func DivExpr() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " / ")
-	} else {
-		fmt.Print(semStack[top-2].id, " / ")
-	}
-	if semStack[top-1].val == 0 {
-		fmt.Errorf("divide 0!!!")
-		os.Exit(1)
-	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
-	}
-	attr := Attribute{
-		tp:     1,
-		len:    1,
-		offset: currentOffset,
-		num:    semStack[top-2].val / semStack[top-1].val,
-	}
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 4
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
-}
-
-func LogicAnd() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " and ")
-	} else {
-		fmt.Print(semStack[top-2].id, " and ")
+// fold returns the value of the binary expression x, a op b: the
+// constant the operands fold to, or Unknown if either of them is not a
+// constant the operator applies to. Constants are exact until they are
+// stored: a constant operand of an operation computed at run time must
+// fit in an int, and a constant divisor must not be zero whatever the
+// dividend.
+func (c *context) fold(x interface{}, a Node, op mytoken.Token, b Node) constant.Value {
+	bin := x.(*ast.BinaryExpr)
+	if op == mytoken.QUO && b.val.Kind() == constant.Int && constant.Sign(b.val) == 0 {
+		c.errh(bin.Y.Pos(), "division by zero")
+		return constant.MakeUnknown()
+	}
+	if a.val.Kind() == constant.Unknown || b.val.Kind() == constant.Unknown {
+		if c.representable(bin.X, a.val, intSize) {
+			c.representable(bin.Y, b.val, intSize)
+		}
+		return constant.MakeUnknown()
 	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
+	want := constant.Int
+	switch op {
+	case mytoken.LAND, mytoken.LOR:
+		want = constant.Bool
+	case mytoken.EQL, mytoken.NEQ:
+		want = a.val.Kind()
 	}
-
-	val := semStack[top-2].val * semStack[top-1].val
-	if val != 0 {
-		val = 1
+	if a.val.Kind() != want || b.val.Kind() != want {
+		return constant.MakeUnknown()
 	}
-
-	attr := Attribute{
-		tp:     0,
-		len:    1,
-		offset: currentOffset,
-		num:    val,
+	switch op {
+	case mytoken.EQL, mytoken.NEQ, mytoken.LSS, mytoken.GTR:
+		return constant.MakeBool(constant.Compare(a.val, op, b.val))
 	}
-
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 1
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
+	return constant.BinaryOp(a.val, op, b.val)
 }
 
-func LogicOr() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " or ")
-	} else {
-		fmt.Print(semStack[top-2].id, " or ")
-	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
+// foldUnary returns the value of the unary expression op a, or Unknown
+// if a is not a constant the operator applies to.
+func (c *context) foldUnary(op mytoken.Token, a Node) constant.Value {
+	want := constant.Int
+	if op == mytoken.NOT {
+		want = constant.Bool
 	}
-
-	val := 0
-	if semStack[top-1].val != 0 || semStack[top-2].val != 0 {
-		val = 1
+	if a.val.Kind() != want {
+		return constant.MakeUnknown()
 	}
+	return constant.UnaryOp(op, a.val)
+}
 
-	attr := Attribute{
-		tp:     0,
-		len:    1,
-		offset: currentOffset,
-		num:    val,
+// representable reports whether the value of x, if it is a constant,
+// fits in a variable of size bytes, and reports an error if it doesn't.
+func (c *context) representable(x ast.Expr, val constant.Value, size int) bool {
+	if !constant.Fits(val, size) {
+		c.errh(x.Pos(), "constant "+val.String()+" overflows int")
+		return false
 	}
-
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 1
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
+	return true
 }
 
-func Equal() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " eq ")
-	} else {
-		fmt.Print(semStack[top-2].id, " eq ")
-	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
-	}
+func (c *context) CheckDup(x interface{}, args []interface{}) interface{} {
+	c.checkDup(args[0].(newToken))
+	return nil
+}
 
-	val := 0
-	if semStack[top-1].val == semStack[top-2].val {
-		val = 1
+func (c *context) checkDup(name newToken) {
+	if c.scope.Lookup(name.lit) != nil {
+		// 重复声明变量
+		c.errh(name.pos, name.lit+" redeclared in this block")
 	}
+}
 
+func (c *context) InstallId(x interface{}, args []interface{}) interface{} {
+	name, val := args[0].(newToken), c.operand(args[3])
+	id := name.lit
 	attr := Attribute{
-		tp:     0,
+		offset: c.currentOffset,
 		len:    1,
-		offset: currentOffset,
-		num:    val,
 	}
-
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 1
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
+	c.declare(name, x, attr)
+	c.currentOffset = c.currentOffset + intSize
+	c.representable(args[3].(ast.Expr), val.val, intSize)
+	c.fn.Emit(&ir.Copy{Dst: ir.Var{Name: id}, Src: val.opnd, Define: true})
+	return x
 }
 
-func NotEqual() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " neq ")
-	} else {
-		fmt.Print(semStack[top-2].id, " neq ")
+// InstallArray declares the array $1 of an Element var declaration.
+// The length must be an int literal.
+func (c *context) InstallArray(x interface{}, args []interface{}) interface{} {
+	elem := args[0].(*ast.IndexExpr)
+	id := elem.X.(*ast.Ident)
+	name := newToken{pos: id.NamePos, tok: mytoken.IDENT, lit: id.Name}
+	c.checkDup(name)
+	l := 0
+	switch n := elem.Index.(type) {
+	case *ast.BadExpr:
+		// reported by the parser
+	case *ast.BasicLit:
+		// malformed literals are reported by the scanner
+		if v := constant.MakeFromLiteral(n.Value, n.Kind); v.Kind() == constant.Int && c.representable(n, v, intSize) {
+			n, _ := constant.Int64Val(v)
+			l = int(n)
+		}
+	default:
+		c.errh(n.Pos(), "invalid array length "+types.ExprString(n))
 	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
+	v := make(map[int]int)
+	attr := Attribute{
+		len:    l,
+		offset: c.currentOffset,
+		values: v,
 	}
+	c.declare(name, x, attr)
+	c.currentOffset = c.currentOffset + intSize*l
+	return x
+}
 
-	val := 0
-	if semStack[top-1].val != semStack[top-2].val {
-		val = 1
-	}
+// declare declares the variable name in the current scope; decl is the
+// declaring statement. A name declared twice keeps its first object,
+// CheckDup reports the second declaration.
+func (c *context) declare(name newToken, decl interface{}, attr Attribute) {
+	obj := types.NewObj(types.Var, name.lit)
+	obj.Pos = name.pos
+	obj.Decl = decl
+	obj.Data = attr
+	c.scope.Insert(obj)
+}
 
-	attr := Attribute{
-		tp:     0,
+// newTemp returns a new temporary of size bytes.
+func (c *context) newTemp(size int) ir.Temp {
+	t := c.fn.NewTemp()
+	c.temps[t] = Attribute{
 		len:    1,
-		offset: currentOffset,
-		num:    val,
+		offset: c.currentOffset,
 	}
+	c.currentOffset = c.currentOffset + size
+	return t
+}
 
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 1
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
+// binary emits the code of the binary expression x, $1 op $3, into a
+// temporary of size bytes.
+func (c *context) binary(x interface{}, args []interface{}, op mytoken.Token, size int) interface{} {
+	a, b := c.operand(args[0]), c.operand(args[2])
+	t := c.newTemp(size)
+	c.fn.Emit(&ir.BinOp{Dst: t, Op: op, X: a.opnd, Y: b.opnd})
+	c.operands[x.(ast.Expr)] = Node{pos: a.pos, val: c.fold(x, a, op, b), opnd: t}
+	return x
 }
 
-func Large() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " lg ")
-	} else {
-		fmt.Print(semStack[top-2].id, " lg ")
-	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
-	}
+func (c *context) AddExpr(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.ADD, intSize)
+}
 
-	val := 0
-	if semStack[top-2].val > semStack[top-1].val {
-		val = 1
-	}
+func (c *context) SubExpr(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.SUB, intSize)
+}
 
-	attr := Attribute{
-		tp:     0,
-		len:    1,
-		offset: currentOffset,
-		num:    val,
-	}
+func (c *context) MulExpr(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.MUL, intSize)
+}
 
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 1
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
+func (c *context) DivExpr(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.QUO, intSize)
 }
 
-func Less() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-2].id == "" {
-		fmt.Print(semStack[top-2].val, " le ")
-	} else {
-		fmt.Print(semStack[top-2].id, " le ")
-	}
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
-	}
+func (c *context) LogicAnd(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.LAND, boolSize)
+}
 
-	val := 0
-	if semStack[top-2].val < semStack[top-1].val {
-		val = 1
-	}
+func (c *context) LogicOr(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.LOR, boolSize)
+}
 
-	attr := Attribute{
-		tp:     0,
-		len:    1,
-		offset: currentOffset,
-		num:    val,
-	}
+func (c *context) Equal(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.EQL, boolSize)
+}
 
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 1
-	numTemp++
-	top = top - 2
-	semStack[top] = Node{val: attr.num, id: t}
-	top++
+func (c *context) NotEqual(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.NEQ, boolSize)
 }
 
-func Zprimary() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-1].id == "" {
-		fmt.Print(semStack[top-1].val)
-	} else {
-		fmt.Print(semStack[top-1].id)
-	}
+func (c *context) Large(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.GTR, boolSize)
+}
 
-	attr := Attribute{
-		tp:     1,
-		len:    1,
-		offset: currentOffset,
-		num:    semStack[top-1].val,
-	}
+func (c *context) Less(x interface{}, args []interface{}) interface{} {
+	return c.binary(x, args, mytoken.LSS, boolSize)
+}
 
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 4
-	numTemp++
-	semStack[top-1] = Node{val: attr.num, id: t}
-	fmt.Println("")
+func (c *context) Zprimary(x interface{}, args []interface{}) interface{} {
+	a := c.operand(args[1])
+	t := c.newTemp(intSize)
+	c.fn.Emit(&ir.Copy{Dst: t, Src: a.opnd})
+	c.operands[x.(ast.Expr)] = Node{pos: a.pos, val: c.foldUnary(mytoken.ADD, a), opnd: t}
+	return x
 }
 
-func Fprimary() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-1].id == "" {
-		fmt.Print(-semStack[top-1].val)
+func (c *context) Fprimary(x interface{}, args []interface{}) interface{} {
+	a := c.operand(args[1])
+	t := c.newTemp(intSize)
+	val := c.foldUnary(mytoken.SUB, a)
+	if val.Kind() == constant.Int {
+		// a negative constant is a constant
+		c.fn.Emit(&ir.Copy{Dst: t, Src: ir.Const{Value: val}})
 	} else {
-		fmt.Print("-", semStack[top-1].id)
+		c.fn.Emit(&ir.UnOp{Dst: t, Op: mytoken.SUB, X: a.opnd})
 	}
+	c.operands[x.(ast.Expr)] = Node{pos: a.pos, val: val, opnd: t}
+	return x
+}
 
-	attr := Attribute{
-		tp:     1,
-		len:    1,
-		offset: currentOffset,
-		num:    -semStack[top-1].val,
-	}
+func (c *context) Nprimary(x interface{}, args []interface{}) interface{} {
+	a := c.operand(args[1])
+	t := c.newTemp(boolSize)
+	c.fn.Emit(&ir.UnOp{Dst: t, Op: mytoken.NOT, X: a.opnd})
+	c.operands[x.(ast.Expr)] = Node{pos: a.pos, val: c.foldUnary(mytoken.NOT, a), opnd: t}
+	return x
+}
 
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 4
-	numTemp++
-	semStack[top-1] = Node{val: attr.num, id: t}
-	fmt.Println("")
+// IndexExpr loads the array element x, $1[$2], into a temporary.
+func (c *context) IndexExpr(x interface{}, args []interface{}) interface{} {
+	ix := x.(*ast.IndexExpr)
+	if id, ok := ix.X.(*ast.Ident); ok {
+		// the identifier of an Element
+		c.resolve(id)
+	}
+	base, index := c.operand(ix.X), c.operand(ix.Index)
+	c.representable(ix.Index, index.val, intSize)
+	t := c.newTemp(intSize)
+	c.fn.Emit(&ir.Load{Dst: t, Base: base.opnd, Index: index.opnd})
+	c.operands[ix] = Node{pos: base.pos, val: constant.MakeUnknown(), opnd: t}
+	return x
 }
 
-func Nprimary() {
-	t := "t" + strconv.Itoa(numTemp)
-	fmt.Print(t, " = ")
-	if semStack[top-1].id == "" {
-		fmt.Print("not", semStack[top-1].val)
-	} else {
-		fmt.Print("not", semStack[top-1].id)
-	}
-	val := 0
-	if semStack[top-1].val == 0 {
-		val = 1
-	}
-	attr := Attribute{
-		tp:     0,
-		len:    1,
-		offset: currentOffset,
-		num:    val,
-	}
+func (c *context) For1(x interface{}, args []interface{}) interface{} {
+	lab1, lab2 := c.fn.NewLabel(), c.fn.NewLabel()
+	c.fn.Emit(lab1)
+	c.fn.Emit(&ir.CondJump{Cond: c.operand(args[1]).opnd, Target: lab2})
+	c.lbegin = append(c.lbegin, lab1)
+	c.lend = append(c.lend, lab2)
+	return nil
+}
 
-	SymbolTables[currentTable][t] = attr
-	currentOffset = currentOffset + 1
-	numTemp++
-	semStack[top-1] = Node{val: attr.num, id: t}
-	fmt.Println("")
-}
-
-var Lbegin = make([]string, 100)
-var curlb = 0
-var Lend = make([]string, 100)
-var curle = 0
-
-func For1() {
-	lab1 := "L" + strconv.Itoa(labelnum)
-	labelnum++
-	lab2 := "L" + strconv.Itoa(labelnum)
-	labelnum++
-	fmt.Println(lab1)
-	fmt.Println("if ", semStack[top-1].id, ".false goto ", lab2)
-	Lbegin[curlb] = lab1
-	curlb++
-	Lend[curle] = lab2
-	curle++
-	top--
-}
-
-func NewST() {
-	totalTable++
-	SymbolTables[totalTable] = make(map[string]Attribute)
-	for num := range control[currentTable] {
-		control[totalTable] = append(control[totalTable], num)
-	}
-	control[totalTable] = append(control[totalTable], totalTable)
-	currentTable = totalTable
+func (c *context) NewST(x interface{}, args []interface{}) interface{} {
+	c.scope = types.NewScope(c.scope)
+	return nil
 }
 
-func EndBlock() {
-	if curlb != 0 {
-		fmt.Println("goto ", Lbegin[curlb-1])
-		curlb--
+func (c *context) EndBlock(x interface{}, args []interface{}) interface{} {
+	if n := len(c.lbegin); n != 0 {
+		c.fn.Emit(&ir.Jump{Target: c.lbegin[n-1]})
+		c.lbegin = c.lbegin[:n-1]
 	}
-	if curle != 0 {
-		fmt.Println(Lend[curle-1])
-		curle--
-	}
-	var backSB int = 0
-	for num := range control[currentTable] {
-		if num > backSB && num != currentTable {
-			backSB = num
-		}
+	if n := len(c.lend); n != 0 {
+		c.fn.Emit(c.lend[n-1])
+		c.lend = c.lend[:n-1]
 	}
-	currentTable = backSB
+	c.scope = c.scope.Parent()
+	return x
 }
 
-func Assign() {
-	fmt.Print(semStack[top-2].id, " = ")
-	if semStack[top-1].id == "" {
-		fmt.Println(semStack[top-1].val)
-	} else {
-		fmt.Println(semStack[top-1].id)
+// Assign stores $3 in the variable or the array element $1. The
+// element was loaded by IndexExpr when $1 was reduced; the load is
+// dead.
+func (c *context) Assign(x interface{}, args []interface{}) interface{} {
+	b := c.operand(args[2])
+	c.representable(args[2].(ast.Expr), b.val, intSize)
+	if ix, ok := unparen(args[0]).(*ast.IndexExpr); ok {
+		base, index := c.operand(ix.X), c.operand(ix.Index)
+		c.fn.Emit(&ir.Store{Base: base.opnd, Index: index.opnd, Src: b.opnd})
+		return x
+	}
+	c.fn.Emit(&ir.Copy{Dst: c.operand(args[0]).opnd, Src: b.opnd})
+	return x
+}
+
+// unparen returns x with its parentheses removed.
+func unparen(x interface{}) interface{} {
+	for {
+		p, ok := x.(*ast.ParenExpr)
+		if !ok {
+			return x
+		}
+		x = p.X
 	}
-	top = top - 2
 }
 
-func IF1() {
-	lab2 := "L" + strconv.Itoa(labelnum)
-	labelnum++
-	fmt.Println("if ", semStack[top-1].id, ".false goto ", lab2)
-	Lend[curle] = lab2
-	curle++
-	top--
+func (c *context) IF1(x interface{}, args []interface{}) interface{} {
+	lab2 := c.fn.NewLabel()
+	c.fn.Emit(&ir.CondJump{Cond: c.operand(args[1]).opnd, Target: lab2})
+	c.lend = append(c.lend, lab2)
+	return nil
 }
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"fmt"
+	"myGo/mytoken"
+	"sort"
+)
+
+// Table is a parsing table in the form the parser runs on. The ACTION
+// part is a dense array indexed by state and terminal, the terminals
+// being the tokens of mytoken and the error pseudo-terminal; the GOTO
+// part is indexed by state and nonterminal ID.
+type Table struct {
+	rules    []*Rule
+	lhs      []int    // nonterminal ID of each rule
+	nonterms []string // the nonterminals by ID
+	action   []int32  // state*numTerms + terminal: 0 error, s+1 shift to s, ^r reduce by rule r
+	goTo     []int32  // state*len(nonterms) + nonterminal: the state to go to, or -1
+}
+
+const (
+	errorTerm = int(mytoken.NumTokens) // the column of the error pseudo-terminal
+	numTerms  = errorTerm + 1
+)
+
+// terms maps the strings of the tokens, the terminals of ActionTables,
+// to their columns in the ACTION part.
+var terms = func() map[string]int {
+	m := map[string]int{errorSym: errorTerm}
+	for tok := mytoken.Token(0); tok < mytoken.NumTokens; tok++ {
+		m[tok.String()] = int(tok)
+	}
+	return m
+}()
+
+// NewTable converts actions to a Table. Shifts on nonterminals, the
+// symbols the rules of the reductions define, are gotos; the other
+// shifts and the reductions must be on tokens.
+func NewTable(actions ActionTable) (*Table, error) {
+	t := &Table{}
+	ruleIds := make(map[*Rule]int)
+	ntIds := make(map[string]int)
+	nonterm := func(sym string) int {
+		id, ok := ntIds[sym]
+		if !ok {
+			id = len(t.nonterms)
+			ntIds[sym] = id
+			t.nonterms = append(t.nonterms, sym)
+		}
+		return id
+	}
+
+	// the nonterminals, as in Grammar.isTerminal: symbols with rules
+	lhs := make(map[string]bool)
+	for _, row := range actions {
+		for _, a := range row {
+			if a, ok := a.(Reduce); ok {
+				lhs[a.rule.symbol] = true
+			}
+		}
+	}
+
+	// number the rules and nonterminals in a fixed order
+	rows := make([][]string, len(actions))
+	for i, row := range actions {
+		for sym := range row {
+			rows[i] = append(rows[i], sym)
+		}
+		sort.Strings(rows[i])
+		for _, sym := range rows[i] {
+			switch a := row[sym].(type) {
+			case Shift:
+				if _, ok := terms[sym]; !ok {
+					if !lhs[sym] {
+						return nil, fmt.Errorf("state %d: shift on %q, which is not a token", i, sym)
+					}
+					nonterm(sym)
+				}
+			case Reduce:
+				if _, ok := terms[sym]; !ok {
+					return nil, fmt.Errorf("state %d: reduction on %q, which is not a token", i, sym)
+				}
+				if _, ok := ruleIds[a.rule]; !ok {
+					ruleIds[a.rule] = len(t.rules)
+					t.rules = append(t.rules, a.rule)
+					t.lhs = append(t.lhs, nonterm(a.rule.symbol))
+				}
+			default:
+				return nil, fmt.Errorf("state %d: unknown action %v on %q", i, a, sym)
+			}
+		}
+	}
+
+	t.action = make([]int32, len(actions)*numTerms)
+	t.goTo = make([]int32, len(actions)*len(t.nonterms))
+	for i := range t.goTo {
+		t.goTo[i] = -1
+	}
+	for i, row := range actions {
+		for _, sym := range rows[i] {
+			switch a := row[sym].(type) {
+			case Shift:
+				if term, ok := terms[sym]; ok {
+					t.action[i*numTerms+term] = int32(a.state + 1)
+				} else {
+					t.goTo[i*len(t.nonterms)+ntIds[sym]] = int32(a.state)
+				}
+			case Reduce:
+				t.action[i*numTerms+terms[sym]] = int32(^ruleIds[a.rule])
+			}
+		}
+	}
+	return t, nil
+}
+
+// States returns the number of states of t.
+func (t *Table) States() int {
+	return len(t.action) / numTerms
+}
+
+// lookup returns the action of state on terminal term: 0 for an error,
+// s+1 to shift and go to state s, ^r to reduce by rule r.
+func (t *Table) lookup(state, term int) int32 {
+	return t.action[state*numTerms+term]
+}
+
+// term returns the column of tok.
+func term(tok mytoken.Token) int {
+	if tok < 0 || tok >= mytoken.NumTokens {
+		return int(mytoken.ILLEGAL)
+	}
+	return int(tok)
+}
+
+// next returns the state to go to from state on the left-hand side of
+// rule r, or -1.
+func (t *Table) next(state, r int) int {
+	return int(t.goTo[state*len(t.nonterms)+t.lhs[r]])
+}
@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"myGo/mytoken"
+	"myGo/scanner"
+	"strings"
+	"testing"
+)
+
+func TestNewTable(t *testing.T) {
+	for state, row := range Actions {
+		for sym, action := range row {
+			term, isTerm := terms[sym]
+			switch a := action.(type) {
+			case Shift:
+				if isTerm {
+					if got := MyGo.lookup(state, term); got != int32(a.state+1) {
+						t.Errorf("state %d on %q: got %d, want shift %d", state, sym, got, a.state)
+					}
+					continue
+				}
+				found := false
+				for r, rule := range MyGo.rules {
+					if rule.symbol == sym {
+						found = true
+						if got := MyGo.next(state, r); got != a.state {
+							t.Errorf("state %d: goto on %s: got %d, want %d", state, sym, got, a.state)
+						}
+						break
+					}
+				}
+				if !found {
+					t.Errorf("no rule for %s", sym)
+				}
+			case Reduce:
+				got := MyGo.lookup(state, term)
+				if got >= 0 || MyGo.rules[^got] != a.rule {
+					t.Errorf("state %d on %q: got %d, want reduce %s", state, sym, got, a.rule.Show("->", -1))
+				}
+			}
+		}
+		// the other entries are errors
+		for tok := mytoken.Token(0); tok < mytoken.NumTokens; tok++ {
+			if _, ok := row[tok.String()]; !ok && MyGo.lookup(state, int(tok)) != 0 {
+				t.Errorf("state %d on %q: got %d, want error", state, tok, MyGo.lookup(state, int(tok)))
+			}
+		}
+	}
+	if MyGo.States() != len(Actions) {
+		t.Errorf("got %d states, want %d", MyGo.States(), len(Actions))
+	}
+
+	var g = &Grammar{rules: []*Rule{
+		{symbol: "S", pattern: []string{"A", "b"}},
+		{symbol: "A", pattern: []string{"+"}},
+	}}
+	g.CollectSymbols()
+	if _, err := NewTable(ComputeLALR(g)); err == nil || !strings.Contains(err.Error(), `reduction on "b", which is not a token`) {
+		t.Errorf("got error %v", err)
+	}
+
+	// a misspelled token would be taken for a nonterminal
+	g = &Grammar{rules: []*Rule{
+		{symbol: "S", pattern: []string{"A", "+"}},
+		{symbol: "A", pattern: []string{"identifer"}},
+	}}
+	g.CollectSymbols()
+	if _, err := NewTable(ComputeLALR(g)); err == nil || !strings.Contains(err.Error(), `shift on "identifer", which is not a token`) {
+		t.Errorf("got error %v", err)
+	}
+}
+
+// benchSource returns a myGo program of about n lines.
+func benchSource(n int) []byte {
+	var b strings.Builder
+	b.WriteString("i := 0\nj := 1\n")
+	for k := 0; k < n/4; k++ {
+		fmt.Fprintf(&b, "x%d := (i + %d) * 2 - j / 3\n", k, k+1)
+		fmt.Fprintf(&b, "if x%d > i && j != 0 {\n\ti = i + x%d\n}\n", k, k)
+	}
+	return []byte(b.String())
+}
+
+// benchTokens scans src.
+func benchTokens(src []byte) []newToken {
+	var s scanner.Scanner
+	s.Init(mytoken.Newfile("", 1, len(src)), src, nil, 0)
+	var toks []newToken
+	for {
+		pos, tok, lit := s.Scan()
+		toks = append(toks, newToken{pos, tok, lit})
+		if tok == mytoken.EOF {
+			return toks
+		}
+	}
+}
+
+// The recognizers run the shift and reduce steps of the parser on toks,
+// without building values, to compare the lookups in the two forms of
+// the table.
+
+func recognizeMap(b *testing.B, ac ActionTable, toks []newToken) {
+	stack := []int{0}
+	for _, tok := range toks {
+		for shifted := false; !shifted; {
+			switch a := ac[stack[len(stack)-1]][tok.tok.String()].(type) {
+			case Shift:
+				stack = append(stack, a.state)
+				shifted = true
+			case Reduce:
+				if a.rule.symbol == "Program" {
+					return
+				}
+				if a.rule.pattern[0] != "" {
+					stack = stack[:len(stack)-len(a.rule.pattern)]
+				}
+				stack = append(stack, ac[stack[len(stack)-1]][a.rule.symbol].(Shift).state)
+			default:
+				b.Fatalf("syntax error at %v", tok.tok)
+			}
+		}
+	}
+}
+
+func recognizeTable(b *testing.B, t *Table, toks []newToken) {
+	stack := []int{0}
+	for _, tok := range toks {
+		for shifted := false; !shifted; {
+			a := t.lookup(stack[len(stack)-1], term(tok.tok))
+			switch {
+			case a > 0:
+				stack = append(stack, int(a-1))
+				shifted = true
+			case a < 0:
+				r := int(^a)
+				rule := t.rules[r]
+				if rule.symbol == "Program" {
+					return
+				}
+				if rule.pattern[0] != "" {
+					stack = stack[:len(stack)-len(rule.pattern)]
+				}
+				stack = append(stack, t.next(stack[len(stack)-1], r))
+			default:
+				b.Fatalf("syntax error at %v", tok.tok)
+			}
+		}
+	}
+}
+
+func BenchmarkRecognizeMap(b *testing.B) {
+	toks := benchTokens(benchSource(1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		recognizeMap(b, Actions, toks)
+	}
+}
+
+func BenchmarkRecognizeTable(b *testing.B) {
+	toks := benchTokens(benchSource(1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		recognizeTable(b, MyGo, toks)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	src := benchSource(1000)
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		p := NewTableParser(MyGo)
+		p.SetOutput(io.Discard)
+		if _, err := p.Parse(mytoken.Newfile("", 1, len(src)), src, "Program"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewTable(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := NewTable(Actions); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
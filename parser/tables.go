@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"myGo/codegen"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tables is the compact form of a grammar and its parsing table that
+// pgen writes as Go source. Symbols and rules are referred to by index.
+type Tables struct {
+	Symbols    []string // the terminals, then the nonterminals
+	Terminals  int      // number of terminals in Symbols
+	Rules      []TableRule
+	Precedence []PrecLevel
+	// Actions holds for each state pairs of a symbol and an action: the
+	// state to shift or go to, or ^rule to reduce by rule.
+	Actions [][]int
+}
+
+// TableRule is a rule of Tables.
+type TableRule struct {
+	Symbol  int
+	Pattern []int // nil for an empty rule
+	Prec    int   // the %prec terminal; or -1
+	Action  string
+	Depth   int // number of values a mid-rule action receives
+}
+
+// NewTables packs grammar and its parsing table actions.
+func NewTables(grammar *Grammar, actions ActionTable) *Tables {
+	grammar.CollectSymbols()
+	terms, noterms := grammar.GetTerminalsAndNoTerminals()
+	t := &Tables{Precedence: grammar.precedence}
+	// EOF is a lookahead, though no rule mentions it
+	hasEOF := false
+	for _, sym := range terms {
+		if sym != "" {
+			t.Symbols = append(t.Symbols, sym)
+		}
+		hasEOF = hasEOF || sym == "EOF"
+	}
+	if !hasEOF {
+		t.Symbols = append(t.Symbols, "EOF")
+		sort.Strings(t.Symbols)
+	}
+	t.Terminals = len(t.Symbols)
+	t.Symbols = append(t.Symbols, noterms...)
+
+	symbolIds := make(map[string]int)
+	for i, sym := range t.Symbols {
+		symbolIds[sym] = i
+	}
+	ruleIds := make(map[*Rule]int)
+	for i, rule := range grammar.rules {
+		ruleIds[rule] = i
+		r := TableRule{Symbol: symbolIds[rule.symbol], Prec: -1, Action: rule.action, Depth: rule.depth}
+		if rule.pattern[0] != "" {
+			for _, sym := range rule.pattern {
+				r.Pattern = append(r.Pattern, symbolIds[sym])
+			}
+		}
+		if rule.prec != "" {
+			r.Prec = symbolIds[rule.prec]
+		}
+		t.Rules = append(t.Rules, r)
+	}
+
+	for _, row := range actions {
+		var pairs []int
+		for sym, action := range row {
+			switch a := action.(type) {
+			case Shift:
+				pairs = append(pairs, symbolIds[sym], a.state)
+			case Reduce:
+				pairs = append(pairs, symbolIds[sym], ^ruleIds[a.rule])
+			}
+		}
+		sortPairs(pairs)
+		t.Actions = append(t.Actions, pairs)
+	}
+	return t
+}
+
+// sortPairs sorts the pairs of a row of Tables.Actions by symbol.
+func sortPairs(pairs []int) {
+	n := len(pairs) / 2
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return pairs[2*idx[i]] < pairs[2*idx[j]] })
+	sorted := make([]int, 0, len(pairs))
+	for _, i := range idx {
+		sorted = append(sorted, pairs[2*i], pairs[2*i+1])
+	}
+	copy(pairs, sorted)
+}
+
+// Load unpacks the grammar and its parsing table.
+func (t *Tables) Load() (*Grammar, ActionTable) {
+	g := &Grammar{precedence: t.Precedence, tokens: make(SymbolSet)}
+	for _, sym := range t.Symbols[:t.Terminals] {
+		g.tokens.Add(sym)
+	}
+	for _, r := range t.Rules {
+		rule := &Rule{symbol: t.Symbols[r.Symbol], pattern: []string{""}, action: r.Action, depth: r.Depth}
+		if len(r.Pattern) > 0 {
+			rule.pattern = make([]string, len(r.Pattern))
+			for i, sym := range r.Pattern {
+				rule.pattern[i] = t.Symbols[sym]
+			}
+		}
+		if r.Prec >= 0 {
+			rule.prec = t.Symbols[r.Prec]
+		}
+		g.rules = append(g.rules, rule)
+	}
+	g.CollectSymbols()
+
+	actions := make(ActionTable, len(t.Actions))
+	for i, pairs := range t.Actions {
+		row := make(map[string]Action, len(pairs)/2)
+		for j := 0; j < len(pairs); j += 2 {
+			sym, a := t.Symbols[pairs[j]], pairs[j+1]
+			if a >= 0 {
+				row[sym] = Shift{a}
+			} else {
+				row[sym] = Reduce{g.rules[^a]}
+			}
+		}
+		actions[i] = row
+	}
+	return g, actions
+}
+
+// WriteGo writes t to w as the Go declaration of a variable name in
+// package pkg.
+func (t *Tables) WriteGo(w *codegen.Writer, name, pkg string) {
+	q := "parser."
+	if pkg == "parser" {
+		q = ""
+	}
+	w.Linef("var %s = &%sTables{", name, q)
+	w.Line("Symbols: []string{")
+	for i, sym := range t.Symbols {
+		if i == t.Terminals {
+			w.Line("// nonterminals")
+		}
+		w.Linef("%q, // %d", sym, i)
+	}
+	w.Line("},")
+	w.Linef("Terminals: %d,", t.Terminals)
+
+	w.Linef("Rules: []%sTableRule{", q)
+	for i, r := range t.Rules {
+		pattern := "nil"
+		if r.Pattern != nil {
+			pattern = "[]int{" + joinInts(r.Pattern) + "}"
+		}
+		w.Linef("{%d, %s, %d, %q, %d}, // %d: %s", r.Symbol, pattern, r.Prec, r.Action, r.Depth, i, t.showRule(r))
+	}
+	w.Line("},")
+
+	w.Linef("Precedence: []%sPrecLevel{", q)
+	for _, level := range t.Precedence {
+		terms := make([]string, len(level.Terms))
+		for i, term := range level.Terms {
+			terms[i] = strconv.Quote(term)
+		}
+		assoc := [...]string{Left: "Left", Right: "Right", Nonassoc: "Nonassoc"}[level.Assoc]
+		w.Linef("{%s%s, []string{%s}},", q, assoc, strings.Join(terms, ", "))
+	}
+	w.Line("},")
+
+	w.Line("Actions: [][]int{")
+	for i, pairs := range t.Actions {
+		w.Linef("%d: {%s},", i, joinInts(pairs))
+	}
+	w.Line("},")
+	w.Line("}")
+}
+
+func (t *Tables) showRule(r TableRule) string {
+	s := t.Symbols[r.Symbol] + " ->"
+	for _, sym := range r.Pattern {
+		s += " " + t.Symbols[sym]
+	}
+	if r.Action != "" {
+		s += " {" + r.Action + "}"
+	}
+	return s
+}
+
+func joinInts(a []int) string {
+	s := make([]string, len(a))
+	for i, n := range a {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,216 @@
+// Code generated by pgen from mygo.grammar; DO NOT EDIT.
+
+package parser
+
+var myGo = &Tables{
+	Symbols: []string{
+		"!",          // 0
+		"!=",         // 1
+		"&&",         // 2
+		"(",          // 3
+		")",          // 4
+		"*",          // 5
+		"+",          // 6
+		"-",          // 7
+		"/",          // 8
+		":=",         // 9
+		";",          // 10
+		"<",          // 11
+		"=",          // 12
+		"==",         // 13
+		">",          // 14
+		"EOF",        // 15
+		"[",          // 16
+		"]",          // 17
+		"break",      // 18
+		"continue",   // 19
+		"else",       // 20
+		"error",      // 21
+		"for",        // 22
+		"identifier", // 23
+		"if",         // 24
+		"int",        // 25
+		"var",        // 26
+		"{",          // 27
+		"||",         // 28
+		"}",          // 29
+		// nonterminals
+		"@CheckDup",     // 30
+		"@For1",         // 31
+		"@IF1",          // 32
+		"@NewST",        // 33
+		"Assignment",    // 34
+		"Block",         // 35
+		"Declaration",   // 36
+		"Element",       // 37
+		"Expression",    // 38
+		"ForClause",     // 39
+		"ForStmt",       // 40
+		"IfStmt",        // 41
+		"Index",         // 42
+		"IndexableExpr", // 43
+		"Literal",       // 44
+		"Operand",       // 45
+		"PrimaryExpr",   // 46
+		"Program",       // 47
+		"SimpleStmt",    // 48
+		"Statement",     // 49
+		"StatementList", // 50
+	},
+	Terminals: 30,
+	Rules: []TableRule{
+		{47, []int{50}, -1, "", 0},                     // 0: Program -> StatementList
+		{40, []int{22, 38, 31, 35}, -1, "", 0},         // 1: ForStmt -> for Expression @For1 Block
+		{31, nil, -1, "For1", 2},                       // 2: @For1 -> {For1}
+		{40, []int{22, 39, 35}, -1, "", 0},             // 3: ForStmt -> for ForClause Block
+		{39, []int{48, 10, 38, 10, 48}, -1, "", 0},     // 4: ForClause -> SimpleStmt ; Expression ; SimpleStmt
+		{41, []int{24, 38, 32, 35}, -1, "", 0},         // 5: IfStmt -> if Expression @IF1 Block
+		{32, nil, -1, "IF1", 2},                        // 6: @IF1 -> {IF1}
+		{41, []int{24, 38, 32, 35, 20, 41}, -1, "", 0}, // 7: IfStmt -> if Expression @IF1 Block else IfStmt
+		{41, []int{24, 38, 32, 35, 20, 35}, -1, "", 0}, // 8: IfStmt -> if Expression @IF1 Block else Block
+		{34, []int{38, 12, 38}, -1, "Assign", 0},       // 9: Assignment -> Expression = Expression {Assign}
+		{49, []int{36}, -1, "", 0},                     // 10: Statement -> Declaration
+		{49, []int{48}, -1, "", 0},                     // 11: Statement -> SimpleStmt
+		{49, []int{18}, -1, "", 0},                     // 12: Statement -> break
+		{49, []int{19}, -1, "", 0},                     // 13: Statement -> continue
+		{49, []int{35}, -1, "", 0},                     // 14: Statement -> Block
+		{49, []int{41}, -1, "", 0},                     // 15: Statement -> IfStmt
+		{49, []int{40}, -1, "", 0},                     // 16: Statement -> ForStmt
+		{49, []int{21}, -1, "", 0},                     // 17: Statement -> error
+		{49, nil, -1, "", 0},                           // 18: Statement ->
+		{48, []int{38}, -1, "", 0},                     // 19: SimpleStmt -> Expression
+		{48, []int{34}, -1, "", 0},                     // 20: SimpleStmt -> Assignment
+		{38, []int{6, 46}, -1, "ZPrimary", 0},          // 21: Expression -> + PrimaryExpr {ZPrimary}
+		{38, []int{7, 46}, -1, "FPrimary", 0},          // 22: Expression -> - PrimaryExpr {FPrimary}
+		{38, []int{0, 46}, -1, "NPrimary", 0},          // 23: Expression -> ! PrimaryExpr {NPrimary}
+		{38, []int{46}, -1, "", 0},                     // 24: Expression -> PrimaryExpr
+		{38, []int{38, 28, 38}, -1, "LogicOr", 0},      // 25: Expression -> Expression || Expression {LogicOr}
+		{38, []int{38, 2, 38}, -1, "LogicAnd", 0},      // 26: Expression -> Expression && Expression {LogicAnd}
+		{38, []int{38, 13, 38}, -1, "Equal", 0},        // 27: Expression -> Expression == Expression {Equal}
+		{38, []int{38, 1, 38}, -1, "NotEqual", 0},      // 28: Expression -> Expression != Expression {NotEqual}
+		{38, []int{38, 14, 38}, -1, "Large", 0},        // 29: Expression -> Expression > Expression {Large}
+		{38, []int{38, 11, 38}, -1, "Less", 0},         // 30: Expression -> Expression < Expression {Less}
+		{38, []int{38, 6, 38}, -1, "AddExpr", 0},       // 31: Expression -> Expression + Expression {AddExpr}
+		{38, []int{38, 7, 38}, -1, "SubExpr", 0},       // 32: Expression -> Expression - Expression {SubExpr}
+		{38, []int{38, 5, 38}, -1, "MulExpr", 0},       // 33: Expression -> Expression * Expression {MulExpr}
+		{38, []int{38, 8, 38}, -1, "DivExpr", 0},       // 34: Expression -> Expression / Expression {DivExpr}
+		{46, []int{23}, -1, "Id2Operand", 0},           // 35: PrimaryExpr -> identifier {Id2Operand}
+		{46, []int{43}, -1, "", 0},                     // 36: PrimaryExpr -> IndexableExpr
+		{43, []int{45}, -1, "", 0},                     // 37: IndexableExpr -> Operand
+		{43, []int{37}, -1, "IndexExpr", 0},            // 38: IndexableExpr -> Element {IndexExpr}
+		{43, []int{43, 42}, -1, "IndexExpr", 0},        // 39: IndexableExpr -> IndexableExpr Index {IndexExpr}
+		{37, []int{23, 42}, -1, "", 0},                 // 40: Element -> identifier Index
+		{42, []int{16, 38, 17}, -1, "", 0},             // 41: Index -> [ Expression ]
+		{42, []int{16, 21, 17}, -1, "", 0},             // 42: Index -> [ error ]
+		{45, []int{44}, -1, "", 0},                     // 43: Operand -> Literal
+		{45, []int{3, 38, 4}, -1, "", 0},               // 44: Operand -> ( Expression )
+		{45, []int{3, 21, 4}, -1, "", 0},               // 45: Operand -> ( error )
+		{44, []int{25}, -1, "Lexval", 0},               // 46: Literal -> int {Lexval}
+		{36, []int{23, 30, 9, 38}, -1, "InstallId", 0}, // 47: Declaration -> identifier @CheckDup := Expression {InstallId}
+		{30, nil, -1, "CheckDup", 1},                   // 48: @CheckDup -> {CheckDup}
+		{36, []int{37, 26}, -1, "InstallArray", 0},     // 49: Declaration -> Element var {InstallArray}
+		{35, []int{27, 33, 50, 29}, -1, "EndBlock", 0}, // 50: Block -> { @NewST StatementList } {EndBlock}
+		{33, nil, -1, "NewST", 1},                      // 51: @NewST -> {NewST}
+		{50, []int{50, 10, 49}, -1, "", 0},             // 52: StatementList -> StatementList ; Statement
+		{50, []int{49}, -1, "", 0},                     // 53: StatementList -> Statement
+	},
+	Precedence: []PrecLevel{
+		{Left, []string{"||"}},
+		{Left, []string{"&&"}},
+		{Left, []string{"==", "!=", "<", ">"}},
+		{Left, []string{"+", "-"}},
+		{Left, []string{"*", "/"}},
+	},
+	Actions: [][]int{
+		0:  {0, 1, 3, 2, 6, 3, 7, 4, 10, -19, 15, -19, 18, 19, 19, 20, 21, 21, 22, 22, 23, 23, 24, 24, 25, 25, 27, 26, 34, 5, 35, 6, 36, 7, 37, 8, 38, 9, 40, 10, 41, 11, 43, 12, 44, 13, 45, 14, 46, 15, 48, 16, 49, 17, 50, 18},
+		1:  {3, 2, 23, 29, 25, 25, 37, 27, 43, 12, 44, 13, 45, 14, 46, 28},
+		2:  {0, 1, 3, 2, 6, 3, 7, 4, 21, 31, 23, 29, 25, 25, 37, 27, 38, 30, 43, 12, 44, 13, 45, 14, 46, 15},
+		3:  {3, 2, 23, 29, 25, 25, 37, 27, 43, 12, 44, 13, 45, 14, 46, 32},
+		4:  {3, 2, 23, 29, 25, 25, 37, 27, 43, 12, 44, 13, 45, 14, 46, 33},
+		5:  {10, -21, 15, -21, 27, -21, 29, -21},
+		6:  {10, -15, 15, -15, 29, -15},
+		7:  {10, -11, 15, -11, 29, -11},
+		8:  {1, -39, 2, -39, 5, -39, 6, -39, 7, -39, 8, -39, 10, -39, 11, -39, 12, -39, 13, -39, 14, -39, 15, -39, 16, -39, 26, 34, 28, -39, 29, -39},
+		9:  {1, 35, 2, 36, 5, 37, 6, 38, 7, 39, 8, 40, 10, -20, 11, 41, 12, 42, 13, 43, 14, 44, 15, -20, 27, -20, 28, 45, 29, -20},
+		10: {10, -17, 15, -17, 29, -17},
+		11: {10, -16, 15, -16, 29, -16},
+		12: {1, -37, 2, -37, 4, -37, 5, -37, 6, -37, 7, -37, 8, -37, 10, -37, 11, -37, 12, -37, 13, -37, 14, -37, 15, -37, 16, 47, 17, -37, 27, -37, 28, -37, 29, -37, 42, 46},
+		13: {1, -44, 2, -44, 4, -44, 5, -44, 6, -44, 7, -44, 8, -44, 10, -44, 11, -44, 12, -44, 13, -44, 14, -44, 15, -44, 16, -44, 17, -44, 27, -44, 28, -44, 29, -44},
+		14: {1, -38, 2, -38, 4, -38, 5, -38, 6, -38, 7, -38, 8, -38, 10, -38, 11, -38, 12, -38, 13, -38, 14, -38, 15, -38, 16, -38, 17, -38, 27, -38, 28, -38, 29, -38},
+		15: {1, -25, 2, -25, 4, -25, 5, -25, 6, -25, 7, -25, 8, -25, 10, -25, 11, -25, 12, -25, 13, -25, 14, -25, 15, -25, 17, -25, 27, -25, 28, -25, 29, -25},
+		16: {10, -12, 15, -12, 29, -12},
+		17: {10, -54, 15, -54, 29, -54},
+		18: {10, 48, 15, -1},
+		19: {10, -13, 15, -13, 29, -13},
+		20: {10, -14, 15, -14, 29, -14},
+		21: {10, -18, 15, -18, 29, -18},
+		22: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 34, 5, 37, 27, 38, 49, 39, 50, 43, 12, 44, 13, 45, 14, 46, 15, 48, 51},
+		23: {1, -36, 2, -36, 5, -36, 6, -36, 7, -36, 8, -36, 9, -49, 10, -36, 11, -36, 12, -36, 13, -36, 14, -36, 15, -36, 16, 47, 28, -36, 29, -36, 30, 52, 42, 53},
+		24: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 54, 43, 12, 44, 13, 45, 14, 46, 15},
+		25: {1, -47, 2, -47, 4, -47, 5, -47, 6, -47, 7, -47, 8, -47, 10, -47, 11, -47, 12, -47, 13, -47, 14, -47, 15, -47, 16, -47, 17, -47, 27, -47, 28, -47, 29, -47},
+		26: {0, -52, 3, -52, 6, -52, 7, -52, 10, -52, 18, -52, 19, -52, 21, -52, 22, -52, 23, -52, 24, -52, 25, -52, 27, -52, 29, -52, 33, 55},
+		27: {1, -39, 2, -39, 4, -39, 5, -39, 6, -39, 7, -39, 8, -39, 10, -39, 11, -39, 12, -39, 13, -39, 14, -39, 15, -39, 16, -39, 17, -39, 27, -39, 28, -39, 29, -39},
+		28: {1, -24, 2, -24, 4, -24, 5, -24, 6, -24, 7, -24, 8, -24, 10, -24, 11, -24, 12, -24, 13, -24, 14, -24, 15, -24, 17, -24, 27, -24, 28, -24, 29, -24},
+		29: {1, -36, 2, -36, 4, -36, 5, -36, 6, -36, 7, -36, 8, -36, 10, -36, 11, -36, 12, -36, 13, -36, 14, -36, 15, -36, 16, 47, 17, -36, 27, -36, 28, -36, 29, -36, 42, 53},
+		30: {1, 35, 2, 36, 4, 56, 5, 37, 6, 38, 7, 39, 8, 40, 11, 41, 13, 43, 14, 44, 28, 45},
+		31: {4, 57},
+		32: {1, -22, 2, -22, 4, -22, 5, -22, 6, -22, 7, -22, 8, -22, 10, -22, 11, -22, 12, -22, 13, -22, 14, -22, 15, -22, 17, -22, 27, -22, 28, -22, 29, -22},
+		33: {1, -23, 2, -23, 4, -23, 5, -23, 6, -23, 7, -23, 8, -23, 10, -23, 11, -23, 12, -23, 13, -23, 14, -23, 15, -23, 17, -23, 27, -23, 28, -23, 29, -23},
+		34: {10, -50, 15, -50, 29, -50},
+		35: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 58, 43, 12, 44, 13, 45, 14, 46, 15},
+		36: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 59, 43, 12, 44, 13, 45, 14, 46, 15},
+		37: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 60, 43, 12, 44, 13, 45, 14, 46, 15},
+		38: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 61, 43, 12, 44, 13, 45, 14, 46, 15},
+		39: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 62, 43, 12, 44, 13, 45, 14, 46, 15},
+		40: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 63, 43, 12, 44, 13, 45, 14, 46, 15},
+		41: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 64, 43, 12, 44, 13, 45, 14, 46, 15},
+		42: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 65, 43, 12, 44, 13, 45, 14, 46, 15},
+		43: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 66, 43, 12, 44, 13, 45, 14, 46, 15},
+		44: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 67, 43, 12, 44, 13, 45, 14, 46, 15},
+		45: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 68, 43, 12, 44, 13, 45, 14, 46, 15},
+		46: {1, -40, 2, -40, 4, -40, 5, -40, 6, -40, 7, -40, 8, -40, 10, -40, 11, -40, 12, -40, 13, -40, 14, -40, 15, -40, 16, -40, 17, -40, 27, -40, 28, -40, 29, -40},
+		47: {0, 1, 3, 2, 6, 3, 7, 4, 21, 70, 23, 29, 25, 25, 37, 27, 38, 69, 43, 12, 44, 13, 45, 14, 46, 15},
+		48: {0, 1, 3, 2, 6, 3, 7, 4, 10, -19, 15, -19, 18, 19, 19, 20, 21, 21, 22, 22, 23, 23, 24, 24, 25, 25, 27, 26, 29, -19, 34, 5, 35, 6, 36, 7, 37, 8, 38, 9, 40, 10, 41, 11, 43, 12, 44, 13, 45, 14, 46, 15, 48, 16, 49, 71},
+		49: {1, 35, 2, 36, 5, 37, 6, 38, 7, 39, 8, 40, 10, -20, 11, 41, 12, 42, 13, 43, 14, 44, 27, -3, 28, 45, 31, 72},
+		50: {27, 26, 35, 73},
+		51: {10, 74},
+		52: {9, 75},
+		53: {1, -41, 2, -41, 4, -41, 5, -41, 6, -41, 7, -41, 8, -41, 10, -41, 11, -41, 12, -41, 13, -41, 14, -41, 15, -41, 16, -41, 17, -41, 26, -41, 27, -41, 28, -41, 29, -41},
+		54: {1, 35, 2, 36, 5, 37, 6, 38, 7, 39, 8, 40, 11, 41, 13, 43, 14, 44, 27, -7, 28, 45, 32, 76},
+		55: {0, 1, 3, 2, 6, 3, 7, 4, 10, -19, 18, 19, 19, 20, 21, 21, 22, 22, 23, 23, 24, 24, 25, 25, 27, 26, 29, -19, 34, 5, 35, 6, 36, 7, 37, 8, 38, 9, 40, 10, 41, 11, 43, 12, 44, 13, 45, 14, 46, 15, 48, 16, 49, 17, 50, 77},
+		56: {1, -45, 2, -45, 4, -45, 5, -45, 6, -45, 7, -45, 8, -45, 10, -45, 11, -45, 12, -45, 13, -45, 14, -45, 15, -45, 16, -45, 17, -45, 27, -45, 28, -45, 29, -45},
+		57: {1, -46, 2, -46, 4, -46, 5, -46, 6, -46, 7, -46, 8, -46, 10, -46, 11, -46, 12, -46, 13, -46, 14, -46, 15, -46, 16, -46, 17, -46, 27, -46, 28, -46, 29, -46},
+		58: {1, -29, 2, -29, 4, -29, 5, 37, 6, 38, 7, 39, 8, 40, 10, -29, 11, -29, 12, -29, 13, -29, 14, -29, 15, -29, 17, -29, 27, -29, 28, -29, 29, -29},
+		59: {1, 35, 2, -27, 4, -27, 5, 37, 6, 38, 7, 39, 8, 40, 10, -27, 11, 41, 12, -27, 13, 43, 14, 44, 15, -27, 17, -27, 27, -27, 28, -27, 29, -27},
+		60: {1, -34, 2, -34, 4, -34, 5, -34, 6, -34, 7, -34, 8, -34, 10, -34, 11, -34, 12, -34, 13, -34, 14, -34, 15, -34, 17, -34, 27, -34, 28, -34, 29, -34},
+		61: {1, -32, 2, -32, 4, -32, 5, 37, 6, -32, 7, -32, 8, 40, 10, -32, 11, -32, 12, -32, 13, -32, 14, -32, 15, -32, 17, -32, 27, -32, 28, -32, 29, -32},
+		62: {1, -33, 2, -33, 4, -33, 5, 37, 6, -33, 7, -33, 8, 40, 10, -33, 11, -33, 12, -33, 13, -33, 14, -33, 15, -33, 17, -33, 27, -33, 28, -33, 29, -33},
+		63: {1, -35, 2, -35, 4, -35, 5, -35, 6, -35, 7, -35, 8, -35, 10, -35, 11, -35, 12, -35, 13, -35, 14, -35, 15, -35, 17, -35, 27, -35, 28, -35, 29, -35},
+		64: {1, -31, 2, -31, 4, -31, 5, 37, 6, 38, 7, 39, 8, 40, 10, -31, 11, -31, 12, -31, 13, -31, 14, -31, 15, -31, 17, -31, 27, -31, 28, -31, 29, -31},
+		65: {1, 35, 2, 36, 5, 37, 6, 38, 7, 39, 8, 40, 10, -10, 11, 41, 13, 43, 14, 44, 15, -10, 27, -10, 28, 45, 29, -10},
+		66: {1, -28, 2, -28, 4, -28, 5, 37, 6, 38, 7, 39, 8, 40, 10, -28, 11, -28, 12, -28, 13, -28, 14, -28, 15, -28, 17, -28, 27, -28, 28, -28, 29, -28},
+		67: {1, -30, 2, -30, 4, -30, 5, 37, 6, 38, 7, 39, 8, 40, 10, -30, 11, -30, 12, -30, 13, -30, 14, -30, 15, -30, 17, -30, 27, -30, 28, -30, 29, -30},
+		68: {1, 35, 2, 36, 4, -26, 5, 37, 6, 38, 7, 39, 8, 40, 10, -26, 11, 41, 12, -26, 13, 43, 14, 44, 15, -26, 17, -26, 27, -26, 28, -26, 29, -26},
+		69: {1, 35, 2, 36, 5, 37, 6, 38, 7, 39, 8, 40, 11, 41, 13, 43, 14, 44, 17, 78, 28, 45},
+		70: {17, 79},
+		71: {10, -53, 15, -53, 29, -53},
+		72: {27, 26, 35, 80},
+		73: {10, -4, 15, -4, 29, -4},
+		74: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 81, 43, 12, 44, 13, 45, 14, 46, 15},
+		75: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 37, 27, 38, 82, 43, 12, 44, 13, 45, 14, 46, 15},
+		76: {27, 26, 35, 83},
+		77: {10, 48, 29, 84},
+		78: {1, -42, 2, -42, 4, -42, 5, -42, 6, -42, 7, -42, 8, -42, 10, -42, 11, -42, 12, -42, 13, -42, 14, -42, 15, -42, 16, -42, 17, -42, 26, -42, 27, -42, 28, -42, 29, -42},
+		79: {1, -43, 2, -43, 4, -43, 5, -43, 6, -43, 7, -43, 8, -43, 10, -43, 11, -43, 12, -43, 13, -43, 14, -43, 15, -43, 16, -43, 17, -43, 26, -43, 27, -43, 28, -43, 29, -43},
+		80: {10, -2, 15, -2, 29, -2},
+		81: {1, 35, 2, 36, 5, 37, 6, 38, 7, 39, 8, 40, 10, 85, 11, 41, 13, 43, 14, 44, 28, 45},
+		82: {1, 35, 2, 36, 5, 37, 6, 38, 7, 39, 8, 40, 10, -48, 11, 41, 13, 43, 14, 44, 15, -48, 28, 45, 29, -48},
+		83: {10, -6, 15, -6, 20, 86, 29, -6},
+		84: {10, -51, 15, -51, 20, -51, 29, -51},
+		85: {0, 1, 3, 2, 6, 3, 7, 4, 23, 29, 25, 25, 34, 5, 37, 27, 38, 9, 43, 12, 44, 13, 45, 14, 46, 15, 48, 87},
+		86: {24, 24, 27, 26, 35, 88, 41, 89},
+		87: {27, -5},
+		88: {10, -9, 15, -9, 29, -9},
+		89: {10, -8, 15, -8, 29, -8},
+	},
+}
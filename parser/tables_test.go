@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"myGo/codegen"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestTablesUpToDate checks that tables_mygo.go was generated from the
+// current mygo.grammar.
+func TestTablesUpToDate(t *testing.T) {
+	f, err := os.Open("mygo.grammar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := LoadGrammar(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, _, err := Generate(g, Options{LALR: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(NewTables(g, actions), myGo) {
+		t.Error("tables_mygo.go is out of date, run go generate")
+	}
+}
+
+func TestTablesLoad(t *testing.T) {
+	g, actions := myGo.Load()
+	if !reflect.DeepEqual(NewTables(g, actions), myGo) {
+		t.Error("NewTables does not invert Load")
+	}
+	for _, rule := range g.rules {
+		switch rule.action {
+		case "AddExpr":
+			if len(rule.pattern) != 3 || g.RulePrecedence(rule) != 4 {
+				t.Errorf("got rule %s {AddExpr}", rule.Show("->", -1))
+			}
+		case "For1":
+			if rule.symbol != "@For1" || rule.pattern[0] != "" || rule.depth != 2 {
+				t.Errorf("got rule %s {For1} after %d symbols", rule.Show("->", -1), rule.depth)
+			}
+		}
+	}
+	if level, assoc := g.Precedence("*"); level != 5 || assoc != Left {
+		t.Errorf(`Precedence("*"): got %d %v`, level, assoc)
+	}
+}
+
+func TestTablesWriteGo(t *testing.T) {
+	w := &codegen.Writer{}
+	w.Line("package p")
+	w.Line(`import "myGo/parser"`)
+	myGo.WriteGo(w, "tables", "p")
+	src, err := w.Fmt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"var tables = &parser.Tables{",
+		`"identifier", //`,
+		"{parser.Left, []string{\"||\"}},",
+		"// 0: Program -> StatementList",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("output lacks %s", want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"myGo/mytoken"
+	"testing"
+)
+
+func TestErrorList(t *testing.T) {
+	var list ErrorList
+	if list.Err() != nil {
+		t.Errorf("empty list: got error %v", list.Err())
+	}
+	pos := func(file string, line, col int) mytoken.Position {
+		return mytoken.Position{Filename: file, Line: line, Column: col}
+	}
+	list.Add(pos("b.go", 1, 1), "b1")
+	list.Add(pos("a.go", 2, 5), "a2 second")
+	list.Add(pos("a.go", 2, 5), "a2 first")
+	list.Add(pos("a.go", 1, 9), "a1")
+	list.Add(pos("a.go", 2, 1), "a2")
+
+	list.Sort()
+	var got []string
+	for _, e := range list {
+		got = append(got, e.Msg)
+	}
+	if want := "[a1 a2 a2 first a2 second b1]"; fmt.Sprint(got) != want {
+		t.Errorf("sorted: got %s, want %s", fmt.Sprint(got), want)
+	}
+	if got, want := list.Error(), "a.go:1:9: a1 (and 4 more errors)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	list.RemoveMultiples()
+	var buf bytes.Buffer
+	PrintError(&buf, list.Err())
+	if got, want := buf.String(), "a.go:1:9: a1\na.go:2:1: a2\nb.go:1:1: b1\n"; got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	PrintError(&buf, errors.New("other"))
+	if buf.String() != "other\n" {
+		t.Errorf("got %q, want %q", buf.String(), "other\n")
+	}
+
+	list.Reset()
+	if len(list) != 0 || list.Error() != "no errors" {
+		t.Errorf("after Reset: got %v", list)
+	}
+}
+
+func TestErrorListHandler(t *testing.T) {
+	src := "a := \"x\nb := 'yy'\n"
+	var list ErrorList
+	var s Scanner
+	s.Init(mytoken.Newfile("x.go", 1, len(src)), []byte(src), list.Add, 0)
+	for _, tok, _ := s.Scan(); tok != mytoken.EOF; _, tok, _ = s.Scan() {
+	}
+	if len(list) != s.ErrorCount || s.ErrorCount != 2 {
+		t.Fatalf("got %d errors, %d counted, want 2: %v", len(list), s.ErrorCount, list)
+	}
+	if got, want := list[1].Error(), "x.go:2:6: illegal rune literal"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -1,15 +1,20 @@
 package scanner
 
 import (
+	"bytes"
 	"fmt"
 	"myGo/mytoken"
 	"path/filepath"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
 )
 
 // An ErrorHandler may be provided to Scanner.Init. If a syntax error is
 // encountered and a handler was installed, the handler is called with a
 // position and an error message. The position points to the beginning of
-// the offending token.
+// the offending token. The Add method of an ErrorList is a handler that
+// collects the errors.
 //
 type ErrorHandler func(pos mytoken.Position, msg string)
 
@@ -46,9 +51,18 @@ func (s *Scanner) next() {
 			s.lineOffset = s.offset
 			s.file.AddLine(s.offset)
 		}
-		r, w := rune(s.src[s.offset]), 1
-		if r == 0 {
-			s.error(s.offset, "illegal character")
+		r, w := rune(s.src[s.rdOffset]), 1
+		switch {
+		case r == 0:
+			s.error(s.offset, "illegal character NUL")
+		case r >= utf8.RuneSelf:
+			// not ASCII
+			r, w = utf8.DecodeRune(s.src[s.rdOffset:])
+			if r == utf8.RuneError && w == 1 {
+				s.error(s.offset, "illegal UTF-8 encoding")
+			} else if r == bom && s.offset > 0 {
+				s.error(s.offset, "illegal byte order mark")
+			}
 		}
 		s.rdOffset += w
 		s.ch = r
@@ -62,13 +76,22 @@ func (s *Scanner) next() {
 	}
 }
 
+// peek returns the byte following the most recently read character without
+// advancing the scanner. If the scanner is at EOF, peek returns 0.
+func (s *Scanner) peek() byte {
+	if s.rdOffset < len(s.src) {
+		return s.src[s.rdOffset]
+	}
+	return 0
+}
+
 // A mode value is a set of flags (or 0).
 // They control scanner behavior.
 //
 type Mode uint
 
 const (
-	ScanComments Mode = 1 << iota
+	ScanComments Mode = 1 << iota // return comments as COMMENT tokens
 	dontInsertSemis
 )
 
@@ -102,38 +125,198 @@ func (s *Scanner) error(offs int, msg string) {
 	s.ErrorCount++
 }
 
+// isLetter and isDigit accept the letters and digits of identifiers,
+// as in the Go spec: Unicode letters and _, and Unicode decimal digits.
 func isLetter(ch rune) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' || ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
 func isDigit(ch rune) bool {
+	return isDecimal(ch) || ch >= utf8.RuneSelf && unicode.IsDigit(ch)
+}
+
+func isDecimal(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
 func (s *Scanner) skipWhitespace() {
-	for s.ch == ' ' || s.ch == '\t' || s.ch == '\n' || s.ch == '\r' {
+	for s.ch == ' ' || s.ch == '\t' || s.ch == '\n' && !s.insertSemi || s.ch == '\r' {
 		s.next()
 	}
 }
 
 func (s *Scanner) scanComment() string {
-	// initial '/' already consumed; s.ch == '*'
+	// initial '/' already consumed; s.ch == '/' || s.ch == '*'
 	offs := s.offset - 1 // position of initial '/'
+	next := -1           // position immediately following the comment; < 0 means invalid comment
+
+	if s.ch == '/' {
+		//-style comment
+		// (the final '\n' is not considered part of the comment)
+		s.next()
+		for s.ch != '\n' && s.ch >= 0 {
+			s.next()
+		}
+		// if we are at '\n', the position following the comment is afterwards
+		next = s.offset
+		if s.ch == '\n' {
+			next++
+		}
+		goto exit
+	}
+
+	/*-style comment */
 	s.next()
 	for s.ch >= 0 {
 		ch := s.ch
 		s.next()
 		if ch == '*' && s.ch == '/' {
 			s.next()
+			next = s.offset
 			goto exit
 		}
 	}
 	s.error(offs, "comment not terminated")
 exit:
 	lit := s.src[offs:s.offset]
+	// a //-comment line may end in "\r\n"
+	if len(lit) >= 2 && lit[1] == '/' && lit[len(lit)-1] == '\r' {
+		lit = lit[:len(lit)-1]
+	}
+
+	// interpret line directives
+	// (//line directives must start at the beginning of the current line)
+	if next >= 0 && (lit[1] == '*' || offs == s.lineOffset) && bytes.HasPrefix(lit[2:], linePrefix) {
+		s.updateLineInfo(next, offs, lit)
+	}
 	return string(lit)
 }
 
+var linePrefix = []byte("line ")
+
+// updateLineInfo parses the line directive comment text, found at offs,
+// and records that the source from next on comes from the file, line
+// and column it names. The forms are
+//
+//	//line filename:line:col
+//	//line filename:line
+//	/*line filename:line:col*/
+//	/*line filename:line*/
+//
+// A directive without a filename keeps the current one if it gives a
+// column. Relative filenames are taken relative to the directory of the
+// file being scanned.
+func (s *Scanner) updateLineInfo(next, offs int, text []byte) {
+	// extract comment text
+	if text[1] == '*' {
+		text = text[:len(text)-2] // lop off trailing "*/"
+	}
+	text = text[7:] // lop off leading "//line " or "/*line "
+	offs += 7
+
+	i, n, ok := trailingDigits(text)
+	if i == 0 {
+		return // ignore (not a line directive)
+	}
+	if !ok {
+		// text has a suffix :xxx but xxx is not a number
+		s.error(offs+i, "invalid line number: "+string(text[i:]))
+		return
+	}
+
+	// cap the line and column numbers well below the int32 limit
+	const maxLineCol = 1<<30 - 1
+	var line, col int
+	i2, n2, ok2 := trailingDigits(text[:i-1])
+	if ok2 {
+		//line filename:line:col
+		i, i2 = i2, i
+		line, col = n2, n
+		if col == 0 || col > maxLineCol {
+			s.error(offs+i2, "invalid column number: "+string(text[i2:]))
+			return
+		}
+		text = text[:i2-1] // lop off ":col"
+	} else {
+		//line filename:line
+		line = n
+	}
+	if line == 0 || line > maxLineCol {
+		s.error(offs+i, "invalid line number: "+string(text[i:]))
+		return
+	}
+
+	filename := string(text[:i-1]) // lop off ":line"
+	if filename == "" && ok2 {
+		filename = s.file.Position(s.file.Pos(offs)).Filename
+	} else if filename != "" {
+		filename = filepath.Clean(filename)
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(s.dir, filename)
+		}
+	}
+	s.file.AddLineColumnInfo(next, filename, line, col)
+}
+
+// trailingDigits returns the index after the last ':' of text and the
+// number that follows it, if it is one. The index is 0 if there is no
+// ':'.
+func trailingDigits(text []byte) (int, int, bool) {
+	i := bytes.LastIndexByte(text, ':') // look from the right, filenames may contain ':'
+	if i < 0 {
+		return 0, 0, false
+	}
+	n, err := strconv.ParseUint(string(text[i+1:]), 10, 0)
+	return i + 1, int(n), err == nil
+}
+
+// findLineEnd reports whether the comments starting at the current '/'
+// reach the end of the line, or of the file, before the next token. It
+// leaves the scanner where it was.
+func (s *Scanner) findLineEnd() bool {
+	// initial '/' already consumed
+
+	defer func(offs int) {
+		// reset scanner state to where it was upon calling findLineEnd
+		s.ch = '/'
+		s.offset = offs
+		s.rdOffset = offs + 1
+		s.next() // consume initial '/' again
+	}(s.offset - 1)
+
+	// read ahead until a newline, EOF, or non-comment token is found
+	for s.ch == '/' || s.ch == '*' {
+		if s.ch == '/' {
+			//-style comment always contains a newline
+			return true
+		}
+		/*-style comment: look for newline */
+		s.next()
+		for s.ch >= 0 {
+			ch := s.ch
+			if ch == '\n' {
+				return true
+			}
+			s.next()
+			if ch == '*' && s.ch == '/' {
+				s.next()
+				break
+			}
+		}
+		s.skipWhitespace() // s.insertSemi is set
+		if s.ch < 0 || s.ch == '\n' {
+			return true
+		}
+		if s.ch != '/' {
+			// non-comment token
+			return false
+		}
+		s.next() // consume '/'
+	}
+
+	return false
+}
+
 func (s *Scanner) scanIdentifier() string {
 	offset := s.offset
 	for isLetter(s.ch) || isDigit(s.ch) {
@@ -146,107 +329,243 @@ func digitVal(ch rune) int {
 	switch {
 	case '0' <= ch && ch <= '9':
 		return int(ch - '0')
-	case 'a' <= ch && ch <= 'f':
-		return int(ch - 'a' + 10)
-	case 'A' <= ch && ch <= 'F':
-		return int(ch - 'A' + 10)
-	default:
-		return 16
+	case 'a' <= lower(ch) && lower(ch) <= 'f':
+		return int(lower(ch) - 'a' + 10)
 	}
+	return 16 // larger than any legal digit val
 }
 
-func (s *Scanner) scanMantissa(base int) {
-	for digitVal(s.ch) < base {
-		s.next()
+func lower(ch rune) rune { return ('a' - 'A') | ch } // returns lower-case ch iff ch is ASCII letter
+func isHex(ch rune) bool { return '0' <= ch && ch <= '9' || 'a' <= lower(ch) && lower(ch) <= 'f' }
+
+// digits accepts the sequence { digit | '_' }.
+// If base <= 10, digits accepts any decimal digit but records
+// the offset (relative to the source start) of a digit >= base
+// in *invalid, if *invalid < 0.
+// digits returns a bitset describing whether the sequence contained
+// digits (bit 0 is set), or separators '_' (bit 1 is set).
+func (s *Scanner) digits(base int, invalid *int) (digsep int) {
+	if base <= 10 {
+		max := rune('0' + base)
+		for isDecimal(s.ch) || s.ch == '_' {
+			ds := 1
+			if s.ch == '_' {
+				ds = 2
+			} else if s.ch >= max && *invalid < 0 {
+				*invalid = s.offset // record invalid rune offset
+			}
+			digsep |= ds
+			s.next()
+		}
+	} else {
+		for isHex(s.ch) || s.ch == '_' {
+			ds := 1
+			if s.ch == '_' {
+				ds = 2
+			}
+			digsep |= ds
+			s.next()
+		}
 	}
+	return
 }
 
-func (s *Scanner) scanNumber(seenDecimalPoint bool) (mytoken.Token, string) {
+// scanNumber scans an integer or floating-point literal as the Go spec
+// defines them: with 0x, 0o, 0 or 0b prefixes, '_' separators, and
+// decimal or hexadecimal exponents.
+func (s *Scanner) scanNumber() (mytoken.Token, string) {
 	offs := s.offset
-	tok := mytoken.INT
+	tok := mytoken.ILLEGAL
 
-	if seenDecimalPoint {
-		offs--
-		tok = mytoken.FLOAT
-		s.scanMantissa(10)
-		goto exponent
-	}
-	if s.ch == '0' {
-		// int or float
-		offs := s.offset
-		s.next()
-		// hexadecimal int
-		if s.ch == 'x' || s.ch == 'X' {
+	base := 10        // number base
+	prefix := rune(0) // one of 0 (decimal), '0' (0-octal), 'x', 'o', or 'b'
+	digsep := 0       // bit 0: digit present, bit 1: '_' present
+	invalid := -1     // index of invalid digit in literal, or < 0
+
+	// integer part
+	if s.ch != '.' {
+		tok = mytoken.INT
+		if s.ch == '0' {
 			s.next()
-			s.scanMantissa(16)
-			if s.offset-offs <= 2 {
-				// only "0x" or "0X"
-				s.error(offs, "illegal hexadecimal number")
-			}
-		} else {
-			// octal int or float
-			seenDecimalDigit := false
-			s.scanMantissa(8)
-			if s.ch == '8' || s.ch == '9' {
-				// illegal octal int or float
-				seenDecimalDigit = true
-				s.scanMantissa(10)
-			}
-			if s.ch == '.' || s.ch == 'e' || s.ch == 'E' {
-				goto fraction
-			}
-			if seenDecimalDigit {
-				s.error(offs, "illegal octal number")
+			switch lower(s.ch) {
+			case 'x':
+				s.next()
+				base, prefix = 16, 'x'
+			case 'o':
+				s.next()
+				base, prefix = 8, 'o'
+			case 'b':
+				s.next()
+				base, prefix = 2, 'b'
+			default:
+				base, prefix = 8, '0'
+				digsep = 1 // leading 0
 			}
 		}
-		goto exit
+		digsep |= s.digits(base, &invalid)
 	}
 
-	// decimal int or float
-	s.scanMantissa(10)
-fraction:
+	// fractional part
 	if s.ch == '.' {
 		tok = mytoken.FLOAT
+		if prefix == 'o' || prefix == 'b' {
+			s.error(s.offset, "invalid radix point in "+litname(prefix))
+		}
 		s.next()
-		s.scanMantissa(10)
+		digsep |= s.digits(base, &invalid)
 	}
 
-exponent:
-	if s.ch == 'e' || s.ch == 'E' {
-		tok = mytoken.FLOAT
+	if digsep&1 == 0 {
+		s.error(s.offset, litname(prefix)+" has no digits")
+	}
+
+	// exponent
+	if e := lower(s.ch); e == 'e' || e == 'p' {
+		switch {
+		case e == 'e' && prefix != 0 && prefix != '0':
+			s.error(s.offset, fmt.Sprintf("%q exponent requires decimal mantissa", s.ch))
+		case e == 'p' && prefix != 'x':
+			s.error(s.offset, fmt.Sprintf("%q exponent requires hexadecimal mantissa", s.ch))
+		}
 		s.next()
-		if s.ch == '-' || s.ch == '+' {
+		tok = mytoken.FLOAT
+		if s.ch == '+' || s.ch == '-' {
 			s.next()
 		}
-		if digitVal(s.ch) < 10 {
-			s.scanMantissa(10)
-		} else {
-			s.error(offs, "illegal floating-point exponent")
+		ds := s.digits(10, nil)
+		digsep |= ds
+		if ds&1 == 0 {
+			s.error(s.offset, "exponent has no digits")
 		}
+	} else if prefix == 'x' && tok == mytoken.FLOAT {
+		s.error(s.offset, "hexadecimal mantissa requires a 'p' exponent")
 	}
-exit:
-	return tok, string(s.src[offs:s.offset])
+
+	lit := string(s.src[offs:s.offset])
+	if tok == mytoken.INT && invalid >= 0 {
+		s.error(invalid, fmt.Sprintf("invalid digit %q in %s", lit[invalid-offs], litname(prefix)))
+	}
+	if digsep&2 != 0 {
+		if i := invalidSep(lit); i >= 0 {
+			s.error(offs+i, "'_' must separate successive digits")
+		}
+	}
+
+	return tok, lit
+}
+
+func litname(prefix rune) string {
+	switch prefix {
+	case 'x':
+		return "hexadecimal literal"
+	case 'o', '0':
+		return "octal literal"
+	case 'b':
+		return "binary literal"
+	}
+	return "decimal literal"
+}
+
+// invalidSep returns the index of the first invalid separator in x, or -1.
+func invalidSep(x string) int {
+	x1 := ' ' // prefix char, we only care if it's 'x'
+	d := '.'  // digit, one of '_', '0' (a digit), or '.' (anything else)
+	i := 0
+
+	// a prefix counts as a digit
+	if len(x) >= 2 && x[0] == '0' {
+		x1 = lower(rune(x[1]))
+		if x1 == 'x' || x1 == 'o' || x1 == 'b' {
+			d = '0'
+			i = 2
+		}
+	}
+
+	// mantissa and exponent
+	for ; i < len(x); i++ {
+		p := d // previous digit
+		d = rune(x[i])
+		switch {
+		case d == '_':
+			if p != '0' {
+				return i
+			}
+		case isDecimal(d) || x1 == 'x' && isHex(d):
+			d = '0'
+		default:
+			if p == '_' {
+				return i - 1
+			}
+			d = '.'
+		}
+	}
+	if d == '_' {
+		return len(x) - 1
+	}
+
+	return -1
 }
 
 // scanEscape parses an escape sequence where rune is the accepted
 // escaped quote. In case of a syntax error, it stops at the offending
 // character (without consuming it) and returns false. Otherwise
-// it returns true.(diff go, it only support anscii)
+// it returns true.
 func (s *Scanner) scanEscape(quote rune) bool {
 	offs := s.offset
+
+	var n int
+	var base, max uint32
 	switch s.ch {
 	case 'a', 'b', 'f', 'n', 'r', 't', 'v', '\\', quote:
 		s.next()
 		return true
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		n, base, max = 3, 8, 255
+	case 'x':
+		s.next()
+		n, base, max = 2, 16, 255
+	case 'u':
+		s.next()
+		n, base, max = 4, 16, unicode.MaxRune
+	case 'U':
+		s.next()
+		n, base, max = 8, 16, unicode.MaxRune
 	default:
-		s.error(offs, "unknown escape sequence")
+		msg := "unknown escape sequence"
+		if s.ch < 0 {
+			msg = "escape sequence not terminated"
+		}
+		s.error(offs, msg)
+		return false
+	}
+
+	var x uint32
+	for n > 0 {
+		d := uint32(digitVal(s.ch))
+		if d >= base {
+			msg := fmt.Sprintf("illegal character %#U in escape sequence", s.ch)
+			if s.ch < 0 {
+				msg = "escape sequence not terminated"
+			}
+			s.error(s.offset, msg)
+			return false
+		}
+		x = x*base + d
+		s.next()
+		n--
+	}
+
+	if x > max || 0xD800 <= x && x < 0xE000 {
+		s.error(offs, "escape sequence is invalid Unicode code point")
 		return false
 	}
+
+	return true
 }
 
 func (s *Scanner) scanString() string {
 	// '"' consumed
-	offs := s.offset
+	offs := s.offset - 1
 	for {
 		ch := s.ch
 		if ch == '\n' || ch < 0 {
@@ -373,72 +692,117 @@ func (s *Scanner) switch4(tok0, tok1 mytoken.Token, ch2 rune, tok2, tok3 mytoken
 }
 
 func (s *Scanner) Scan() (pos mytoken.Pos, tok mytoken.Token, lit string) {
+scanAgain:
 	s.skipWhitespace()
 
 	// current token start
 	pos = s.file.Pos(s.offset)
 
+	// determine token value
+	insertSemi := false
 	switch ch := s.ch; {
 	case isLetter(ch):
 		lit = s.scanIdentifier()
 		if len(lit) > 1 {
 			// keyword are longer than letter
 			tok = mytoken.Lookup(lit)
+			switch tok {
+			case mytoken.IDENT, mytoken.BREAK, mytoken.CONTINUE, mytoken.RETURN:
+				insertSemi = true
+			case mytoken.VAR:
+				// var ends the array declarations of myGo, x [n]var
+				insertSemi = true
+			}
 		} else {
+			insertSemi = true
 			tok = mytoken.IDENT
 		}
-	case isDigit(ch):
-		tok, lit = s.scanNumber(false)
+	case isDecimal(ch) || ch == '.' && isDecimal(rune(s.peek())):
+		insertSemi = true
+		tok, lit = s.scanNumber()
 	default:
 		s.next()
 		switch ch {
 		case -1:
+			if s.insertSemi {
+				s.insertSemi = false // EOF consumed
+				return pos, mytoken.SEMICOLON, "\n"
+			}
 			tok = mytoken.EOF
 		case '\n':
+			// we only reach here if s.insertSemi was
+			// set in the first place and exited early
+			// from s.skipWhitespace()
+			s.insertSemi = false // newline consumed
 			return pos, mytoken.SEMICOLON, "\n"
 		case '"':
+			insertSemi = true
 			tok = mytoken.STRING
 			lit = s.scanString()
 		case '\'':
+			insertSemi = true
 			tok = mytoken.CHAR
 			lit = s.scanRune()
 		case '`':
+			insertSemi = true
 			tok = mytoken.STRING
 			lit = s.scanRawString()
 		case ':':
 			tok = s.switch2(mytoken.COLON, mytoken.DEFINE)
 		case '.':
-			if '0' <= s.ch && s.ch <= '9' {
-				tok, lit = s.scanNumber(true)
-			} else {
-				tok = mytoken.PERIOD
-			}
+			// fractions starting with a '.' are handled by outer switch
+			tok = mytoken.PERIOD
 		case ',':
 			tok = mytoken.COMMA
 		case ';':
 			tok = mytoken.SEMICOLON
+			lit = ";"
 		case '(':
 			tok = mytoken.LPAREN
 		case ')':
+			insertSemi = true
 			tok = mytoken.RPAREN
 		case '[':
 			tok = mytoken.LBRACK
 		case ']':
+			insertSemi = true
 			tok = mytoken.RBRACK
 		case '{':
 			tok = mytoken.LBRACE
 		case '}':
+			insertSemi = true
 			tok = mytoken.RBRACE
 		case '+':
 			tok = s.switch3(mytoken.ADD, mytoken.ADD_ASSIGN, '+', mytoken.INC)
+			if tok == mytoken.INC {
+				insertSemi = true
+			}
 		case '-':
 			tok = s.switch3(mytoken.SUB, mytoken.SUB_ASSIGN, '-', mytoken.DEC)
+			if tok == mytoken.DEC {
+				insertSemi = true
+			}
 		case '*':
 			tok = s.switch2(mytoken.MUL, mytoken.MUL_ASSIGN)
 		case '/':
-			if s.ch == '*' {
-				lit = s.scanComment()
+			if s.ch == '/' || s.ch == '*' {
+				// comment
+				if s.insertSemi && s.findLineEnd() {
+					// reset position to the beginning of the comment
+					s.ch = '/'
+					s.offset = s.file.Offset(pos)
+					s.rdOffset = s.offset + 1
+					s.insertSemi = false // newline consumed
+					return pos, mytoken.SEMICOLON, "\n"
+				}
+				comment := s.scanComment()
+				if s.mode&ScanComments == 0 {
+					// skip comment
+					s.insertSemi = false // newline consumed
+					goto scanAgain
+				}
 				tok = mytoken.COMMENT
+				lit = comment
 			} else {
 				tok = s.switch2(mytoken.QUO, mytoken.QUO_ASSIGN)
 			}
@@ -464,9 +828,17 @@ func (s *Scanner) Scan() (pos mytoken.Pos, tok mytoken.Token, lit string) {
 		case '|':
 			tok = s.switch3(mytoken.OR, mytoken.OR_ASSIGN, '|', mytoken.LOR)
 		default:
+			// the BOM was reported by next already
+			if ch != bom {
+				s.error(s.file.Offset(pos), fmt.Sprintf("illegal character %#U", ch))
+			}
+			insertSemi = s.insertSemi // preserve insertSemi info
 			tok = mytoken.ILLEGAL
 			lit = string(ch)
 		}
 	}
+	if s.mode&dontInsertSemis == 0 {
+		s.insertSemi = insertSemi
+	}
 	return
 }
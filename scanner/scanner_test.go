@@ -0,0 +1,297 @@
+package scanner
+
+import (
+	"myGo/mytoken"
+	"strings"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	src := "a // one\n/* two */ b /* three\n*/"
+	var s Scanner
+	s.Init(mytoken.Newfile("", 1, len(src)), []byte(src), nil, ScanComments)
+	want := []struct {
+		tok mytoken.Token
+		lit string
+	}{
+		{mytoken.IDENT, "a"},
+		{mytoken.SEMICOLON, "\n"},
+		{mytoken.COMMENT, "// one"},
+		{mytoken.COMMENT, "/* two */"},
+		{mytoken.IDENT, "b"},
+		{mytoken.SEMICOLON, "\n"},
+		{mytoken.COMMENT, "/* three\n*/"},
+		{mytoken.EOF, ""},
+	}
+	for _, w := range want {
+		if _, tok, lit := s.Scan(); tok != w.tok || lit != w.lit {
+			t.Errorf("got %s %q, want %s %q", tok, lit, w.tok, w.lit)
+		}
+	}
+
+	// without ScanComments, the comments are skipped
+	s.Init(mytoken.Newfile("", 1, len(src)), []byte(src), nil, 0)
+	for _, w := range want {
+		if w.tok == mytoken.COMMENT {
+			continue
+		}
+		if _, tok, lit := s.Scan(); tok != w.tok || lit != w.lit {
+			t.Errorf("got %s %q, want %s %q", tok, lit, w.tok, w.lit)
+		}
+	}
+}
+
+func TestLineDirectives(t *testing.T) {
+	// the identifiers are named after the positions they should have;
+	// a directive without a column makes the columns unknown
+	src := `a
+//line tmpl.go:10
+tmpl_10
+  tmpl_11 /*line :20:5*/tmpl_20_5 tmpl_20_15
+/*line other.go:7*/ other_7
+other_8
+//line /abs/x.go:3:4
+x_3_4
+ // line directives must start the line: //line ignored.go:1
+x_5_1
+`
+	file := mytoken.Newfile("dir/src.go", 1, len(src))
+	var s Scanner
+	s.Init(file, []byte(src), func(pos mytoken.Position, msg string) { t.Errorf("%s: %s", pos, msg) }, 0)
+	want := map[string]string{
+		"a":          "dir/src.go:1:1",
+		"tmpl_10":    "dir/tmpl.go:10",
+		"tmpl_11":    "dir/tmpl.go:11",
+		"tmpl_20_5":  "dir/tmpl.go:20:5",
+		"tmpl_20_15": "dir/tmpl.go:20:15",
+		"other_7":    "dir/other.go:7",
+		"other_8":    "dir/other.go:8",
+		"x_3_4":      "/abs/x.go:3:4",
+		"x_5_1":      "/abs/x.go:5:1",
+	}
+	n := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == mytoken.EOF {
+			break
+		}
+		if tok != mytoken.IDENT {
+			continue
+		}
+		n++
+		if got := file.Position(pos).String(); got != want[lit] {
+			t.Errorf("%s: got position %s, want %s", lit, got, want[lit])
+		}
+		if got := file.PositionFor(pos, false).Filename; got != "dir/src.go" {
+			t.Errorf("%s: got unadjusted file %s", lit, got)
+		}
+	}
+	if n != len(want) {
+		t.Errorf("got %d identifiers, want %d", n, len(want))
+	}
+}
+
+func TestLineDirectiveErrors(t *testing.T) {
+	for _, test := range []struct {
+		src, err string
+	}{
+		{"//line f.go:x\n", "1:13: invalid line number: x"},
+		{"//line f.go:0\n", "1:13: invalid line number: 0"},
+		{"//line f.go:1:0\n", "1:15: invalid column number: 0"},
+	} {
+		var got string
+		var s Scanner
+		s.Init(mytoken.Newfile("", 1, len(test.src)), []byte(test.src), func(pos mytoken.Position, msg string) {
+			got = pos.String() + ": " + msg
+		}, 0)
+		for _, tok, _ := s.Scan(); tok != mytoken.EOF; _, tok, _ = s.Scan() {
+		}
+		if got != test.err {
+			t.Errorf("%q: got error %q, want %q", test.src, got, test.err)
+		}
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	src := "\ufeffπ := \"é\"\nvar_ü2 x٣ 'ö'"
+	file := mytoken.Newfile("", 1, len(src))
+	var s Scanner
+	s.Init(file, []byte(src), func(pos mytoken.Position, msg string) { t.Errorf("%s: %s", pos, msg) }, 0)
+	for _, want := range []struct {
+		tok mytoken.Token
+		lit string
+		pos string
+	}{
+		{mytoken.IDENT, "π", "1:4"}, // after the BOM
+		{mytoken.DEFINE, "", "1:7"},
+		{mytoken.STRING, `"é"`, "1:10"},
+		{mytoken.SEMICOLON, "\n", "1:14"},
+		{mytoken.IDENT, "var_ü2", "2:1"},
+		{mytoken.IDENT, "x٣", "2:9"},
+		{mytoken.CHAR, "'ö'", "2:13"},
+		{mytoken.SEMICOLON, "\n", "2:17"},
+		{mytoken.EOF, "", "2:17"},
+	} {
+		pos, tok, lit := s.Scan()
+		if tok != want.tok || lit != want.lit || file.Position(pos).String() != want.pos {
+			t.Errorf("got %s %q at %s, want %s %q at %s", tok, lit, file.Position(pos), want.tok, want.lit, want.pos)
+		}
+	}
+}
+
+func TestUnicodeErrors(t *testing.T) {
+	for _, test := range []struct {
+		src  string
+		errs []string
+	}{
+		{"a \xff b", []string{"1:3: illegal UTF-8 encoding", "1:3: illegal character U+FFFD '�'"}},
+		{"a \ufeff", []string{"1:3: illegal byte order mark"}},
+		{"a € b", []string{"1:3: illegal character U+20AC '€'"}},
+		{"'\xe2\x82'", []string{"1:2: illegal UTF-8 encoding"}},
+	} {
+		var errs []string
+		var s Scanner
+		s.Init(mytoken.Newfile("", 1, len(test.src)), []byte(test.src), func(pos mytoken.Position, msg string) {
+			errs = append(errs, pos.String()+": "+msg)
+		}, 0)
+		for _, tok, _ := s.Scan(); tok != mytoken.EOF; _, tok, _ = s.Scan() {
+		}
+		if len(errs) == 0 || len(errs) < len(test.errs) {
+			t.Errorf("%q: got errors %q, want %q", test.src, errs, test.errs)
+			continue
+		}
+		for i, err := range test.errs {
+			if errs[i] != err {
+				t.Errorf("%q: got errors %q, want %q", test.src, errs, test.errs)
+				break
+			}
+		}
+	}
+}
+
+func TestSemicolons(t *testing.T) {
+	// scan returns the tokens of src, with "\\n" for the semicolons the
+	// scanner inserts
+	scan := func(src string, mode Mode) string {
+		var s Scanner
+		s.Init(mytoken.Newfile("", 1, len(src)), []byte(src), func(pos mytoken.Position, msg string) { t.Errorf("%s: %s", pos, msg) }, mode)
+		var toks []string
+		for {
+			_, tok, lit := s.Scan()
+			switch {
+			case tok == mytoken.EOF:
+				return strings.Join(toks, " ")
+			case tok == mytoken.SEMICOLON && lit == "\n":
+				toks = append(toks, `\n`)
+			case lit != "" && tok != mytoken.SEMICOLON:
+				toks = append(toks, lit)
+			default:
+				toks = append(toks, tok.String())
+			}
+		}
+	}
+	for _, test := range []struct{ src, want string }{
+		{"", ""},
+		{"\n\n", ""},
+		{"a", `a \n`},
+		{"a\n", `a \n`},
+		{"a;\n", "a ;"},
+		{"a\n\nb\n", `a \n b \n`},
+		{"1\n1.5\n.5\n'a'\n\"s\"\n`r`\n", `1 \n 1.5 \n .5 \n 'a' \n "s" \n `+"`r`"+` \n`},
+		{"break\ncontinue\nreturn\n", `break \n continue \n return \n`},
+		{"a [3]var\na[1]\n", `a [ 3 ] var \n a [ 1 ] \n`},
+		{"i++\ni--\n", `i ++ \n i -- \n`},
+		{"f()\na[i]\n{}\n", `f ( ) \n a [ i ] \n { } \n`},
+		// no semicolon after operators, opening brackets and keywords
+		{"a +\nb\n", `a + b \n`},
+		{"a &&\nb ==\nc\n", `a && b == c \n`},
+		{"if x {\n}\n", `if x { } \n`},
+		{"f(\na,\n)\n", `f ( a , ) \n`},
+		{"for\nx := [\n", `for x := [`},
+		{"else\nvar\n", `else var \n`},
+		// comments
+		{"a // c\nb", `a \n b \n`},
+		{"a /* c */\nb", `a \n b \n`},
+		{"a /* c */ b", `a b \n`},
+		{"a /* c\n */ b", `a \n b \n`},
+		{"a /* c */ /* d */\n", `a \n`},
+		{"a /* c */ // d", `a \n`},
+		{"+ // c\nb", `+ b \n`},
+		{"a /* c */", `a \n`},
+	} {
+		if got := scan(test.src, 0); got != test.want {
+			t.Errorf("%q: got %s, want %s", test.src, got, test.want)
+		}
+	}
+
+	// with comments, the semicolon comes before the comment that ends
+	// the line
+	if got, want := scan("a /* c */ // d\n", ScanComments), `a \n /* c */ // d`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := scan("a\nb\n", dontInsertSemis), "a b"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestLiterals(t *testing.T) {
+	for _, test := range []struct {
+		src string
+		tok mytoken.Token
+		err string // the first error, if any
+	}{
+		{"0", mytoken.INT, ""},
+		{"1_000_000", mytoken.INT, ""},
+		{"0x_Ff", mytoken.INT, ""},
+		{"0o17", mytoken.INT, ""},
+		{"017", mytoken.INT, ""},
+		{"0b1010", mytoken.INT, ""},
+		{"1.5e-3", mytoken.FLOAT, ""},
+		{".5", mytoken.FLOAT, ""},
+		{"1.", mytoken.FLOAT, ""},
+		{"0x1.8p1", mytoken.FLOAT, ""},
+		{"0x", mytoken.INT, "1:3: hexadecimal literal has no digits"},
+		{"0b102", mytoken.INT, "1:5: invalid digit '2' in binary literal"},
+		{"089", mytoken.INT, "1:2: invalid digit '8' in octal literal"},
+		{"1__0", mytoken.INT, "1:3: '_' must separate successive digits"},
+		{"10_", mytoken.INT, "1:3: '_' must separate successive digits"},
+		{"1e", mytoken.FLOAT, "1:3: exponent has no digits"},
+		{"0x1.8", mytoken.FLOAT, "1:6: hexadecimal mantissa requires a 'p' exponent"},
+		{"0b1.0", mytoken.FLOAT, "1:4: invalid radix point in binary literal"},
+		{"1p3", mytoken.FLOAT, "1:2: 'p' exponent requires hexadecimal mantissa"},
+		{`'a'`, mytoken.CHAR, ""},
+		{`'\n'`, mytoken.CHAR, ""},
+		{`'\377'`, mytoken.CHAR, ""},
+		{`'\x7f'`, mytoken.CHAR, ""},
+		{`'é'`, mytoken.CHAR, ""},
+		{`'\U0010FFFF'`, mytoken.CHAR, ""},
+		{`'\400'`, mytoken.CHAR, "1:3: escape sequence is invalid Unicode code point"},
+		{`'\uD800'`, mytoken.CHAR, "1:3: escape sequence is invalid Unicode code point"},
+		{`'\U00110000'`, mytoken.CHAR, "1:3: escape sequence is invalid Unicode code point"},
+		{`'\xg0'`, mytoken.CHAR, "1:4: illegal character U+0067 'g' in escape sequence"},
+		{`'\q'`, mytoken.CHAR, "1:3: unknown escape sequence"},
+		{`'ab'`, mytoken.CHAR, "1:1: illegal rune literal"},
+		{`'a`, mytoken.CHAR, "1:1: rune literal not terminated"},
+		{`"a\tbé"`, mytoken.STRING, ""},
+		{`"a`, mytoken.STRING, "1:1: string literal not terminated"},
+		{`"\x`, mytoken.STRING, "1:4: escape sequence not terminated"},
+		{"`a\nb`", mytoken.STRING, ""},
+		{"`a", mytoken.STRING, "1:1: raw string literal not terminated"},
+	} {
+		var errs []string
+		var s Scanner
+		s.Init(mytoken.Newfile("", 1, len(test.src)), []byte(test.src), func(pos mytoken.Position, msg string) {
+			errs = append(errs, pos.String()+": "+msg)
+		}, dontInsertSemis)
+		_, tok, lit := s.Scan()
+		if tok != test.tok || lit != test.src {
+			t.Errorf("%s: got %s %q", test.src, tok, lit)
+		}
+		switch {
+		case test.err == "" && len(errs) > 0:
+			t.Errorf("%s: got errors %q", test.src, errs)
+		case test.err != "" && (len(errs) == 0 || errs[0] != test.err):
+			t.Errorf("%s: got errors %q, want %s", test.src, errs, test.err)
+		}
+	}
+}
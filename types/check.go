@@ -0,0 +1,311 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"myGo/ast"
+	"myGo/mytoken"
+	"myGo/scanner"
+)
+
+// Info holds the results of type checking.
+type Info struct {
+	Types map[ast.Expr]Type      // the type of every expression but the declared identifiers
+	Defs  map[*ast.Ident]*Object // the objects declared by identifiers
+	Uses  map[*ast.Ident]*Object // the objects the other identifiers denote
+}
+
+// Check type-checks f, the syntax tree of file, and records the types
+// of its expressions and the objects of its identifiers in info, if it
+// is not nil. It returns the errors as a scanner.ErrorList sorted by
+// position, or nil. The statements of f are checked in a scope nested
+// in Universe.
+func Check(file *mytoken.File, f *ast.File, info *Info) error {
+	c := &checker{file: file, info: info, scope: NewScope(Universe)}
+	c.stmtList(f.Stmts)
+	c.errors.Sort()
+	return c.errors.Err()
+}
+
+// A checker holds the state of a type check.
+type checker struct {
+	file   *mytoken.File
+	info   *Info
+	scope  *Scope // the innermost scope
+	loops  int    // number of enclosing for statements
+	errors scanner.ErrorList
+}
+
+func (c *checker) errorf(pos mytoken.Pos, format string, args ...interface{}) {
+	var position mytoken.Position
+	if c.file != nil {
+		position = c.file.Position(pos)
+	}
+	c.errors.Add(position, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) openScope()  { c.scope = NewScope(c.scope) }
+func (c *checker) closeScope() { c.scope = c.scope.parent }
+
+// declare declares the variable id of type typ in the current scope.
+func (c *checker) declare(id *ast.Ident, typ Type, decl interface{}) {
+	obj := NewObj(Var, id.Name)
+	obj.Pos = id.NamePos
+	obj.Decl = decl
+	obj.Type = typ
+	if alt := c.scope.Insert(obj); alt != nil {
+		c.errorf(id.NamePos, "%s redeclared in this block", id.Name)
+		return
+	}
+	if c.info != nil && c.info.Defs != nil {
+		c.info.Defs[id] = obj
+	}
+}
+
+func (c *checker) stmtList(list []ast.Stmt) {
+	for _, s := range list {
+		c.stmt(s)
+	}
+}
+
+func (c *checker) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case nil, *ast.BadStmt:
+		// errors reported by the parser
+
+	case *ast.ExprStmt:
+		c.expr(s.X)
+
+	case *ast.AssignStmt:
+		if s.Tok == mytoken.DEFINE {
+			typ := c.expr(s.Rhs[0])
+			if id, ok := s.Lhs[0].(*ast.Ident); ok {
+				c.declare(id, typ, s)
+			}
+			return
+		}
+		lhs, rhs := c.expr(s.Lhs[0]), c.expr(s.Rhs[0])
+		if !c.assignable(s.Lhs[0]) {
+			c.errorf(s.Lhs[0].Pos(), "cannot assign to %s", ExprString(s.Lhs[0]))
+			return
+		}
+		if isValid(lhs) && isValid(rhs) && !Identical(lhs, rhs) {
+			c.errorf(s.Rhs[0].Pos(), "cannot use %s (type %s) as type %s in assignment", ExprString(s.Rhs[0]), rhs, lhs)
+		}
+
+	case *ast.DeclStmt:
+		d, ok := s.Decl.(*ast.GenDecl)
+		if !ok {
+			return
+		}
+		for _, spec := range d.Specs {
+			if spec, ok := spec.(*ast.ValueSpec); ok {
+				typ := c.typ(spec.Type)
+				for _, id := range spec.Names {
+					c.declare(id, typ, s)
+				}
+			}
+		}
+
+	case *ast.LabeledStmt:
+		c.stmt(s.Stmt)
+
+	case *ast.BranchStmt:
+		if c.loops == 0 {
+			c.errorf(s.TokPos, "%s is not in a loop", s.Tok)
+		}
+
+	case *ast.BlockStmt:
+		c.openScope()
+		c.stmtList(s.List)
+		c.closeScope()
+
+	case *ast.IfStmt:
+		c.openScope()
+		defer c.closeScope()
+		c.stmt(s.Init)
+		c.cond(s.Cond, "if")
+		c.stmt(s.Body)
+		c.stmt(s.Else)
+
+	case *ast.ForStmt:
+		c.openScope()
+		defer c.closeScope()
+		c.stmt(s.Init)
+		if s.Cond != nil {
+			c.cond(s.Cond, "for")
+		}
+		c.stmt(s.Post)
+		c.loops++
+		c.stmt(s.Body)
+		c.loops--
+
+	default:
+		c.errorf(s.Pos(), "unsupported statement")
+	}
+}
+
+// cond checks the condition x of an if or for statement.
+func (c *checker) cond(x ast.Expr, stmt string) {
+	if typ := c.expr(x); isValid(typ) && !isBool(typ) {
+		c.errorf(x.Pos(), "non-boolean condition in %s statement", stmt)
+	}
+}
+
+// assignable reports whether x denotes a variable or an array element.
+func (c *checker) assignable(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.ParenExpr:
+		return c.assignable(x.X)
+	case *ast.Ident:
+		_, obj := c.scope.LookupParent(x.Name)
+		return obj == nil || obj.Kind == Var
+	case *ast.IndexExpr, *ast.BadExpr:
+		return true
+	}
+	return false
+}
+
+// record records the type of x.
+func (c *checker) record(x ast.Expr, typ Type) {
+	if c.info != nil && c.info.Types != nil {
+		c.info.Types[x] = typ
+	}
+}
+
+// expr checks x and returns its type, Invalid if x has errors.
+func (c *checker) expr(x ast.Expr) Type {
+	typ := c.exprInternal(x)
+	c.record(x, typ)
+	return typ
+}
+
+func (c *checker) exprInternal(x ast.Expr) Type {
+	invalid := BasicTypes[Invalid]
+	switch x := x.(type) {
+	case *ast.BadExpr:
+		return invalid
+
+	case *ast.Ident:
+		_, obj := c.scope.LookupParent(x.Name)
+		if obj == nil {
+			c.errorf(x.NamePos, "undefined: %s", x.Name)
+			return invalid
+		}
+		if c.info != nil && c.info.Uses != nil {
+			c.info.Uses[x] = obj
+		}
+		if obj.Kind == Typ {
+			c.errorf(x.NamePos, "%s (type) is not an expression", x.Name)
+			return invalid
+		}
+		return obj.Type
+
+	case *ast.BasicLit:
+		if x.Kind != mytoken.INT {
+			c.errorf(x.ValuePos, "%s literals are not supported", x.Kind)
+			return invalid
+		}
+		return BasicTypes[Int]
+
+	case *ast.ParenExpr:
+		return c.expr(x.X)
+
+	case *ast.UnaryExpr:
+		typ := c.expr(x.X)
+		if !isValid(typ) {
+			return invalid
+		}
+		ok := isInt(typ)
+		if x.Op == mytoken.NOT {
+			ok = isBool(typ)
+		}
+		if !ok {
+			c.errorf(x.OpPos, "invalid operation: operator %s not defined on %s (type %s)", x.Op, ExprString(x.X), typ)
+			return invalid
+		}
+		return typ
+
+	case *ast.BinaryExpr:
+		return c.binary(x)
+
+	case *ast.IndexExpr:
+		typ, index := c.expr(x.X), c.expr(x.Index)
+		if !isValid(typ) {
+			return invalid
+		}
+		a, ok := typ.(*Array)
+		if !ok {
+			c.errorf(x.Pos(), "invalid operation: cannot index %s (type %s)", ExprString(x.X), typ)
+			return invalid
+		}
+		if isValid(index) && !isInt(index) {
+			c.errorf(x.Index.Pos(), "invalid array index %s (type %s must be int)", ExprString(x.Index), index)
+		} else if n, ok := c.constInt(x.Index); ok && (n.Sign() < 0 || n.Cmp(big.NewInt(a.len)) >= 0) {
+			c.errorf(x.Index.Pos(), "invalid array index %s (out of bounds for %d-element array)", ExprString(x.Index), a.len)
+		}
+		return a.elem
+	}
+	c.errorf(x.Pos(), "unsupported expression %s", ExprString(x))
+	return invalid
+}
+
+// binary checks the binary expression x.
+func (c *checker) binary(x *ast.BinaryExpr) Type {
+	invalid := BasicTypes[Invalid]
+	lhs, rhs := c.expr(x.X), c.expr(x.Y)
+	if !isValid(lhs) || !isValid(rhs) {
+		return invalid
+	}
+	if !Identical(lhs, rhs) {
+		c.errorf(x.OpPos, "invalid operation: %s (mismatched types %s and %s)", ExprString(x), lhs, rhs)
+		return invalid
+	}
+
+	var ok bool
+	result := lhs
+	switch x.Op {
+	case mytoken.ADD, mytoken.SUB, mytoken.MUL, mytoken.QUO:
+		ok = isInt(lhs)
+	case mytoken.LAND, mytoken.LOR:
+		ok = isBool(lhs)
+	case mytoken.EQL, mytoken.NEQ:
+		ok, result = true, BasicTypes[Bool]
+	case mytoken.LSS, mytoken.GTR, mytoken.LEQ, mytoken.GEQ:
+		ok, result = isInt(lhs), BasicTypes[Bool]
+	}
+	if !ok {
+		c.errorf(x.OpPos, "invalid operation: operator %s not defined on %s (type %s)", x.Op, ExprString(x.X), lhs)
+		return invalid
+	}
+	return result
+}
+
+// typ returns the type the type expression x denotes.
+func (c *checker) typ(x ast.Expr) Type {
+	invalid := BasicTypes[Invalid]
+	a, ok := x.(*ast.ArrayType)
+	if !ok {
+		c.errorf(x.Pos(), "%s is not a type", ExprString(x))
+		return invalid
+	}
+	n, ok := c.constInt(a.Len)
+	if !ok || n.Sign() < 0 || !n.IsInt64() {
+		c.errorf(a.Len.Pos(), "invalid array length %s", ExprString(a.Len))
+		return invalid
+	}
+	return NewArray(BasicTypes[Int], n.Int64())
+}
+
+// constInt returns the value of x if it is an int literal.
+func (c *checker) constInt(x ast.Expr) (*big.Int, bool) {
+	lit, ok := x.(*ast.BasicLit)
+	if !ok || lit.Kind != mytoken.INT {
+		return nil, false
+	}
+	v, err := mytoken.LiteralValue(lit.Kind, lit.Value)
+	if err != nil {
+		return nil, false
+	}
+	return v.(*big.Int), true
+}
@@ -0,0 +1,142 @@
+package types_test
+
+import (
+	"myGo/ast"
+	"myGo/mytoken"
+	"myGo/parser"
+	"myGo/scanner"
+	"myGo/types"
+	"testing"
+)
+
+// parse parses src, which must be free of syntax errors.
+func parse(t *testing.T, src string) (*mytoken.File, *ast.File) {
+	t.Helper()
+	file := mytoken.Newfile("x.go", 1, len(src))
+	f, err := parser.ParseFile(file, []byte(src), 0)
+	if err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	return file, f
+}
+
+func TestCheck(t *testing.T) {
+	for _, test := range []struct {
+		src  string
+		errs []string
+	}{
+		{"i := 1\nb := i < 2 && !(i == 3)\nb = true\n", nil},
+		{"a [3]var\nz [3]var\ni := a[0]\nz = a\nfor i < 3 {\n\ti = a[i] + 1\n\ta[i] = z[2]\n\tbreak\n}\n", nil},
+		{"i := 1\nif i > 0 {\n\ti := i > 0\n\tif i {\n\t}\n}\n", nil},
+
+		{"i := 1\nb := i && true\n", []string{"x.go:2:8: invalid operation: i && true (mismatched types int and bool)"}},
+		{"b := true && false\nc := b + b\n", []string{"x.go:2:8: invalid operation: operator + not defined on b (type bool)"}},
+		{"i := -(1 < 2)\nj := !1\n", []string{
+			"x.go:1:6: invalid operation: operator - not defined on (1 < 2) (type bool)",
+			"x.go:2:6: invalid operation: operator ! not defined on 1 (type int)",
+		}},
+		{"i := 1\nj := i[0]\n", []string{"x.go:2:6: invalid operation: cannot index i (type int)"}},
+		{"a [3]var\nj := a[1 > 0]\nk := a[3]\n", []string{
+			"x.go:2:8: invalid array index 1 > 0 (type bool must be int)",
+			"x.go:3:8: invalid array index 3 (out of bounds for 3-element array)",
+		}},
+		{"a [2]var\nb [3]var\na = b\nc := a[0][1]\n", []string{
+			"x.go:3:5: cannot use b (type [3]int) as type [2]int in assignment",
+			"x.go:4:6: invalid operation: cannot index a[0] (type int)",
+		}},
+		{"i := 1\nif i {\n}\nfor i + 1 {\n}\n", []string{
+			"x.go:2:4: non-boolean condition in if statement",
+			"x.go:4:5: non-boolean condition in for statement",
+		}},
+		{"a [3]var\ni := 1\ni = i > 0\na = i\n", []string{
+			"x.go:3:5: cannot use i > 0 (type bool) as type int in assignment",
+			"x.go:4:5: cannot use i (type int) as type [3]int in assignment",
+		}},
+		{"true = false\n", []string{"x.go:1:1: cannot assign to true"}},
+		{"i := j + 1\nk := int\n", []string{"x.go:1:6: undefined: j", "x.go:2:6: int (type) is not an expression"}},
+		{"{\n\tk := 1\n}\nk = 2\n", []string{"x.go:4:1: undefined: k"}},
+		{"break\n", []string{"x.go:1:1: break is not in a loop"}},
+		// no errors follow from an invalid operand
+		{"i := j + 1\nb := i && true\nif i {\n}\n", []string{"x.go:1:6: undefined: j"}},
+	} {
+		file, f := parse(t, test.src)
+		err := types.Check(file, f, nil)
+		var got []string
+		if err != nil {
+			for _, e := range err.(scanner.ErrorList) {
+				got = append(got, e.Error())
+			}
+		}
+		if len(got) != len(test.errs) {
+			t.Errorf("%q: got errors %q, want %q", test.src, got, test.errs)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.errs[i] {
+				t.Errorf("%q: got error %q, want %q", test.src, got[i], test.errs[i])
+			}
+		}
+	}
+}
+
+func TestCheckInfo(t *testing.T) {
+	file, f := parse(t, "a [2]var\ni := 1\nb := a[i] > (i + 1)\n")
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.Type),
+		Defs:  make(map[*ast.Ident]*types.Object),
+		Uses:  make(map[*ast.Ident]*types.Object),
+	}
+	if err := types.Check(file, f, info); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"1":              "int",
+		"i":              "int",
+		"a":              "[2]int",
+		"a[i]":           "int",
+		"i + 1":          "int",
+		"(i + 1)":        "int",
+		"a[i] > (i + 1)": "bool",
+	}
+	seen := make(map[string]bool)
+	for x, typ := range info.Types {
+		s := types.ExprString(x)
+		if w, ok := want[s]; !ok || typ.String() != w {
+			t.Errorf("%s: got type %s, want %s", s, typ, w)
+		}
+		seen[s] = true
+	}
+	for s := range want {
+		if !seen[s] {
+			t.Errorf("%s: no type", s)
+		}
+	}
+
+	var defs []string
+	for id, obj := range info.Defs {
+		if obj.Kind != types.Var || obj.Pos != id.Pos() {
+			t.Errorf("%s: got %s object at %d", id.Name, obj.Kind, obj.Pos)
+		}
+		defs = append(defs, id.Name+" "+obj.Type.String())
+	}
+	if len(defs) != 3 {
+		t.Errorf("got definitions %v, want i, a and b", defs)
+	}
+	for id, obj := range info.Uses {
+		if obj.Name != id.Name || info.Defs[ast.NewIdent(id.Name)] != nil {
+			t.Errorf("%s: uses %s", id.Name, obj.Name)
+		}
+		if obj.Pos >= id.Pos() {
+			t.Errorf("%s at %d: uses the object declared at %d", id.Name, id.Pos(), obj.Pos)
+		}
+	}
+}
+
+func TestParseCheckTypes(t *testing.T) {
+	src := []byte("i := 1\nif i {\n}\nb := i && i\n")
+	_, err := parser.ParseFile(mytoken.Newfile("x.go", 1, len(src)), src, parser.CheckTypes)
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) != 2 || list[0].Pos.Line != 2 || list[1].Pos.Line != 4 {
+		t.Errorf("got errors %v, want two on lines 2 and 4", err)
+	}
+}
@@ -0,0 +1,43 @@
+// Package types declares the objects and scopes of myGo programs: what
+// the identifiers of a program denote and where they are visible.
+package types
+
+import "myGo/mytoken"
+
+// ObjKind describes what an object represents.
+type ObjKind int
+
+// The list of possible Object kinds.
+const (
+	Bad ObjKind = iota // for error handling
+	Var                // variable
+	Con                // constant
+	Typ                // type
+	Lbl                // label
+)
+
+var objKindStrings = [...]string{
+	Bad: "bad",
+	Var: "var",
+	Con: "const",
+	Typ: "type",
+	Lbl: "label",
+}
+
+func (kind ObjKind) String() string { return objKindStrings[kind] }
+
+// An Object describes a named language entity such as a variable,
+// constant, type or label.
+type Object struct {
+	Kind ObjKind
+	Name string      // declared name
+	Pos  mytoken.Pos // position of the name in the declaration; or NoPos
+	Decl interface{} // the declaring statement, such as an *ast.AssignStmt; or nil
+	Type Type        // the type of the object, set by the checker; or nil
+	Data interface{} // object-specific data; or nil
+}
+
+// NewObj creates a new object of a given kind and name.
+func NewObj(kind ObjKind, name string) *Object {
+	return &Object{Kind: kind, Name: name}
+}
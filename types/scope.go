@@ -0,0 +1,86 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A Scope maintains the objects declared in a block, and links to the
+// scope of the enclosing block. The scopes of a program nest as its
+// blocks do: the blocks of if and for statements, their else branches
+// and the explicit blocks each open a scope within the enclosing one.
+type Scope struct {
+	parent *Scope
+	elems  map[string]*Object
+}
+
+// NewScope returns a new, empty scope contained in the given parent
+// scope, if any.
+func NewScope(parent *Scope) *Scope {
+	return &Scope{parent: parent}
+}
+
+// Parent returns the scope's containing (parent) scope; or nil.
+func (s *Scope) Parent() *Scope { return s.parent }
+
+// Len returns the number of objects declared in s.
+func (s *Scope) Len() int { return len(s.elems) }
+
+// Names returns the names of the objects declared in s, in sorted
+// order.
+func (s *Scope) Names() []string {
+	names := make([]string, 0, len(s.elems))
+	for name := range s.elems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the object with the given name if it is declared in
+// s, otherwise it returns nil. The enclosing scopes are ignored.
+func (s *Scope) Lookup(name string) *Object {
+	return s.elems[name]
+}
+
+// LookupParent follows the parent chain of scopes starting with s
+// until it finds a scope where Lookup(name) returns a non-nil object,
+// and then returns that scope and object. If no such scope exists, the
+// result is (nil, nil).
+func (s *Scope) LookupParent(name string) (*Scope, *Object) {
+	for ; s != nil; s = s.parent {
+		if obj := s.elems[name]; obj != nil {
+			return s, obj
+		}
+	}
+	return nil, nil
+}
+
+// Insert attempts to insert obj into s. If s already contains an
+// object alt with the same name, Insert leaves s unchanged and returns
+// alt. Otherwise it inserts obj and returns nil.
+func (s *Scope) Insert(obj *Object) (alt *Object) {
+	if alt = s.elems[obj.Name]; alt == nil {
+		if s.elems == nil {
+			s.elems = make(map[string]*Object)
+		}
+		s.elems[obj.Name] = obj
+	}
+	return
+}
+
+// String returns a description of s for debugging: its objects, one
+// per line, in sorted order.
+func (s *Scope) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "scope %p {", s)
+	if len(s.elems) > 0 {
+		b.WriteString("\n")
+		for _, name := range s.Names() {
+			fmt.Fprintf(&b, "\t%s %s\n", s.elems[name].Kind, name)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
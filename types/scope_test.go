@@ -0,0 +1,42 @@
+package types
+
+import "testing"
+
+func TestScope(t *testing.T) {
+	outer := NewScope(nil)
+	x := NewObj(Var, "x")
+	if alt := outer.Insert(x); alt != nil {
+		t.Fatalf("Insert: got %v, want nil", alt)
+	}
+	if alt := outer.Insert(NewObj(Con, "x")); alt != x {
+		t.Errorf("Insert of a second x: got %v, want the first", alt)
+	}
+	if obj := outer.Lookup("x"); obj != x || obj.Kind != Var {
+		t.Errorf("Lookup: got %v", obj)
+	}
+
+	inner := NewScope(outer)
+	if inner.Parent() != outer {
+		t.Error("wrong parent")
+	}
+	if obj := inner.Lookup("x"); obj != nil {
+		t.Errorf("Lookup in the inner scope: got %v, want nil", obj)
+	}
+	if s, obj := inner.LookupParent("x"); s != outer || obj != x {
+		t.Errorf("LookupParent: got %p, %v", s, obj)
+	}
+
+	// the inner x shadows the outer one
+	x2 := NewObj(Var, "x")
+	inner.Insert(x2)
+	inner.Insert(NewObj(Lbl, "L"))
+	if s, obj := inner.LookupParent("x"); s != inner || obj != x2 {
+		t.Errorf("LookupParent after shadowing: got %p, %v", s, obj)
+	}
+	if s, obj := outer.LookupParent("L"); s != nil || obj != nil {
+		t.Errorf("LookupParent of an inner name: got %p, %v", s, obj)
+	}
+	if got := inner.Names(); len(got) != 2 || got[0] != "L" || got[1] != "x" || inner.Len() != 2 {
+		t.Errorf("Names: got %v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package types
+
+import "fmt"
+
+// A Type represents a type of myGo. All types implement the Type
+// interface.
+type Type interface {
+	// String returns a string representation of the type.
+	String() string
+}
+
+// BasicKind describes the kind of basic type.
+type BasicKind int
+
+const (
+	Invalid BasicKind = iota // type is invalid
+
+	Int
+	Bool
+)
+
+// A Basic represents a basic type.
+type Basic struct {
+	kind BasicKind
+	name string
+}
+
+// Kind returns the kind of basic type b.
+func (b *Basic) Kind() BasicKind { return b.kind }
+
+// Name returns the name of basic type b.
+func (b *Basic) Name() string { return b.name }
+
+func (b *Basic) String() string { return b.name }
+
+// BasicTypes holds the basic types, indexed by their kind.
+// BasicTypes[Invalid] is the type of the expressions that have errors.
+var BasicTypes = [...]*Basic{
+	Invalid: {Invalid, "invalid type"},
+	Int:     {Int, "int"},
+	Bool:    {Bool, "bool"},
+}
+
+// An Array represents an array type. Its elements are ints, declared
+// with the var keyword, as in [3]var.
+type Array struct {
+	len  int64
+	elem Type
+}
+
+// NewArray returns a new array type for the given element type and
+// length.
+func NewArray(elem Type, len int64) *Array { return &Array{len, elem} }
+
+// Len returns the length of array a.
+func (a *Array) Len() int64 { return a.len }
+
+// Elem returns the element type of array a.
+func (a *Array) Elem() Type { return a.elem }
+
+func (a *Array) String() string { return fmt.Sprintf("[%d]%s", a.len, a.elem) }
+
+// Identical reports whether x and y are identical types.
+func Identical(x, y Type) bool {
+	switch x := x.(type) {
+	case *Basic:
+		return x == y
+	case *Array:
+		y, ok := y.(*Array)
+		return ok && x.len == y.len && Identical(x.elem, y.elem)
+	}
+	return false
+}
+
+// isValid reports whether t is not the invalid type, so that errors
+// about it would not follow from an earlier error.
+func isValid(t Type) bool { return t != BasicTypes[Invalid] }
+
+func isInt(t Type) bool  { return t == BasicTypes[Int] }
+func isBool(t Type) bool { return t == BasicTypes[Bool] }
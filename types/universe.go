@@ -0,0 +1,19 @@
+package types
+
+// Universe is the scope of the predeclared identifiers: the types int
+// and bool, and the constants true and false. It encloses the scope of
+// every file.
+var Universe = func() *Scope {
+	s := NewScope(nil)
+	for _, t := range []*Basic{BasicTypes[Int], BasicTypes[Bool]} {
+		obj := NewObj(Typ, t.name)
+		obj.Type = t
+		s.Insert(obj)
+	}
+	for _, name := range []string{"true", "false"} {
+		obj := NewObj(Con, name)
+		obj.Type = BasicTypes[Bool]
+		s.Insert(obj)
+	}
+	return s
+}()